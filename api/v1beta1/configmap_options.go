@@ -0,0 +1,383 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// configValueType is the type of the value of an ingress-nginx ConfigMap option.
+type configValueType int
+
+const (
+	configString configValueType = iota
+	configBool
+	configInt
+	// configSize is an NGINX size, e.g. 8k or 1m.
+	configSize
+	// configIntList is a comma-separated list of integers, e.g. 404,503.
+	configIntList
+	// configWorkerProcesses is an integer or auto.
+	configWorkerProcesses
+)
+
+// configOption describes an ingress-nginx ConfigMap option.
+type configOption struct {
+	valueType configValueType
+	// restart is true if ingress-nginx only reads the option when its pods start, instead of reloading NGINX.
+	restart bool
+}
+
+// configOptions are the options of the ingress-nginx ConfigMap.
+// See https://kubernetes.github.io/ingress-nginx/user-guide/nginx-configuration/configmap/.
+var configOptions = map[string]configOption{
+	"access-log-params":                     {valueType: configString},
+	"access-log-path":                       {valueType: configString},
+	"add-headers":                           {valueType: configString},
+	"allow-backend-server-header":           {valueType: configBool},
+	"allow-cross-namespace-resources":       {valueType: configBool},
+	"allow-snippet-annotations":             {valueType: configBool},
+	"annotation-value-word-blocklist":       {valueType: configString},
+	"annotations-risk-level":                {valueType: configString},
+	"bind-address":                          {valueType: configString},
+	"block-cidrs":                           {valueType: configString},
+	"block-referers":                        {valueType: configString},
+	"block-user-agents":                     {valueType: configString},
+	"brotli-level":                          {valueType: configInt},
+	"brotli-min-length":                     {valueType: configInt},
+	"brotli-types":                          {valueType: configString},
+	"client-body-buffer-size":               {valueType: configSize},
+	"client-body-timeout":                   {valueType: configInt},
+	"client-header-buffer-size":             {valueType: configSize},
+	"client-header-timeout":                 {valueType: configInt},
+	"compute-full-forwarded-for":            {valueType: configBool},
+	"custom-http-errors":                    {valueType: configIntList},
+	"debug-connections":                     {valueType: configString},
+	"default-type":                          {valueType: configString},
+	"denylist-source-range":                 {valueType: configString},
+	"disable-access-log":                    {valueType: configBool},
+	"disable-ipv6":                          {valueType: configBool},
+	"disable-ipv6-dns":                      {valueType: configBool},
+	"disable-proxy-intercept-errors":        {valueType: configBool},
+	"enable-access-log-for-default-backend": {valueType: configBool},
+	"enable-aio-write":                      {valueType: configBool},
+	"enable-brotli":                         {valueType: configBool},
+	"enable-modsecurity":                    {valueType: configBool},
+	"enable-multi-accept":                   {valueType: configBool},
+	"enable-ocsp":                           {valueType: configBool},
+	"enable-opentelemetry":                  {valueType: configBool},
+	"enable-opentracing":                    {valueType: configBool},
+	"enable-owasp-modsecurity-crs":          {valueType: configBool},
+	"enable-real-ip":                        {valueType: configBool},
+	"enable-syslog":                         {valueType: configBool},
+	"enable-underscores-in-headers":         {valueType: configBool},
+	"error-log-level":                       {valueType: configString},
+	"error-log-path":                        {valueType: configString},
+	"force-ssl-redirect":                    {valueType: configBool},
+	"forwarded-for-header":                  {valueType: configString},
+	"generate-request-id":                   {valueType: configBool},
+	"geoip2-autoreload-in-minutes":          {valueType: configInt},
+	"global-allowed-response-headers":       {valueType: configString},
+	"grpc-buffer-size-kb":                   {valueType: configInt},
+	"gzip-disable":                          {valueType: configString},
+	"gzip-level":                            {valueType: configInt},
+	"gzip-min-length":                       {valueType: configInt},
+	"gzip-types":                            {valueType: configString},
+	"hide-headers":                          {valueType: configString},
+	"hsts":                                  {valueType: configBool},
+	"hsts-include-subdomains":               {valueType: configBool},
+	"hsts-max-age":                          {valueType: configInt},
+	"hsts-preload":                          {valueType: configBool},
+	"http-access-log-path":                  {valueType: configString},
+	"http-redirect-code":                    {valueType: configInt},
+	"http-snippet":                          {valueType: configString},
+	"http2-max-concurrent-streams":          {valueType: configInt},
+	"http2-max-field-size":                  {valueType: configSize},
+	"http2-max-header-size":                 {valueType: configSize},
+	"http2-max-requests":                    {valueType: configInt},
+	"ignore-invalid-headers":                {valueType: configBool},
+	"keep-alive":                            {valueType: configInt},
+	"keep-alive-requests":                   {valueType: configInt},
+	"large-client-header-buffers":           {valueType: configString},
+	"limit-conn-status-code":                {valueType: configInt},
+	"limit-conn-zone-variable":              {valueType: configString},
+	"limit-rate":                            {valueType: configInt},
+	"limit-rate-after":                      {valueType: configInt},
+	"limit-req-status-code":                 {valueType: configInt},
+	"load-balance":                          {valueType: configString},
+	"location-snippet":                      {valueType: configString},
+	"log-format-escape-json":                {valueType: configBool},
+	"log-format-escape-none":                {valueType: configBool},
+	"log-format-stream":                     {valueType: configString},
+	"log-format-upstream":                   {valueType: configString},
+	"lua-shared-dicts":                      {valueType: configString, restart: true},
+	"main-snippet":                          {valueType: configString},
+	"map-hash-bucket-size":                  {valueType: configInt},
+	"max-worker-connections":                {valueType: configInt},
+	"max-worker-open-files":                 {valueType: configInt},
+	"modsecurity-snippet":                   {valueType: configString},
+	"nginx-status-ipv4-whitelist":           {valueType: configString},
+	"nginx-status-ipv6-whitelist":           {valueType: configString},
+	"no-auth-locations":                     {valueType: configString},
+	"no-tls-redirect-locations":             {valueType: configString},
+	"opentracing-location-operation-name":   {valueType: configString},
+	"opentracing-operation-name":            {valueType: configString},
+	"opentracing-trust-incoming-span":       {valueType: configBool},
+	"plugins":                               {valueType: configString, restart: true},
+	"proxy-add-original-uri-header":         {valueType: configBool},
+	"proxy-body-size":                       {valueType: configSize},
+	"proxy-buffer-size":                     {valueType: configSize},
+	"proxy-buffering":                       {valueType: configString},
+	"proxy-buffers-number":                  {valueType: configInt},
+	"proxy-busy-buffers-size":               {valueType: configSize},
+	"proxy-connect-timeout":                 {valueType: configInt},
+	"proxy-cookie-domain":                   {valueType: configString},
+	"proxy-cookie-path":                     {valueType: configString},
+	"proxy-headers-hash-bucket-size":        {valueType: configInt},
+	"proxy-headers-hash-max-size":           {valueType: configInt},
+	"proxy-http-version":                    {valueType: configString},
+	"proxy-max-temp-file-size":              {valueType: configSize},
+	"proxy-next-upstream":                   {valueType: configString},
+	"proxy-next-upstream-timeout":           {valueType: configInt},
+	"proxy-next-upstream-tries":             {valueType: configInt},
+	"proxy-protocol-header-timeout":         {valueType: configString},
+	"proxy-read-timeout":                    {valueType: configInt},
+	"proxy-real-ip-cidr":                    {valueType: configString},
+	"proxy-redirect-from":                   {valueType: configString},
+	"proxy-redirect-to":                     {valueType: configString},
+	"proxy-request-buffering":               {valueType: configString},
+	"proxy-send-timeout":                    {valueType: configInt},
+	"proxy-set-headers":                     {valueType: configString},
+	"proxy-ssl-location-only":               {valueType: configBool},
+	"proxy-stream-next-upstream":            {valueType: configBool},
+	"proxy-stream-next-upstream-timeout":    {valueType: configString},
+	"proxy-stream-next-upstream-tries":      {valueType: configInt},
+	"proxy-stream-responses":                {valueType: configInt},
+	"proxy-stream-timeout":                  {valueType: configString},
+	"relative-redirects":                    {valueType: configBool},
+	"retry-non-idempotent":                  {valueType: configBool},
+	"reuse-port":                            {valueType: configBool},
+	"server-name-hash-bucket-size":          {valueType: configInt},
+	"server-name-hash-max-size":             {valueType: configInt},
+	"server-snippet":                        {valueType: configString},
+	"server-tokens":                         {valueType: configBool},
+	"service-upstream":                      {valueType: configBool},
+	"skip-access-log-urls":                  {valueType: configString},
+	"ssl-buffer-size":                       {valueType: configSize},
+	"ssl-ciphers":                           {valueType: configString},
+	"ssl-dh-param":                          {valueType: configString},
+	"ssl-early-data":                        {valueType: configBool},
+	"ssl-ecdh-curve":                        {valueType: configString},
+	"ssl-protocols":                         {valueType: configString},
+	"ssl-redirect":                          {valueType: configBool},
+	"ssl-reject-handshake":                  {valueType: configBool},
+	"ssl-session-cache":                     {valueType: configBool},
+	"ssl-session-cache-size":                {valueType: configSize, restart: true},
+	"ssl-session-ticket-key":                {valueType: configString},
+	"ssl-session-tickets":                   {valueType: configBool},
+	"ssl-session-timeout":                   {valueType: configString},
+	"stream-access-log-path":                {valueType: configString},
+	"stream-snippet":                        {valueType: configString},
+	"strict-validate-path-type":             {valueType: configBool},
+	"syslog-host":                           {valueType: configString},
+	"syslog-port":                           {valueType: configInt},
+	"upstream-hash-by":                      {valueType: configString},
+	"upstream-keepalive-connections":        {valueType: configInt},
+	"upstream-keepalive-requests":           {valueType: configInt},
+	"upstream-keepalive-time":               {valueType: configString},
+	"upstream-keepalive-timeout":            {valueType: configInt},
+	"use-forwarded-headers":                 {valueType: configBool},
+	"use-geoip":                             {valueType: configBool},
+	"use-geoip2":                            {valueType: configBool},
+	"use-gzip":                              {valueType: configBool},
+	"use-http2":                             {valueType: configBool},
+	"use-proxy-protocol":                    {valueType: configBool},
+	"variables-hash-bucket-size":            {valueType: configInt},
+	"variables-hash-max-size":               {valueType: configInt},
+	"whitelist-source-range":                {valueType: configString},
+	"worker-cpu-affinity":                   {valueType: configString},
+	"worker-processes":                      {valueType: configWorkerProcesses},
+	"worker-shutdown-timeout":               {valueType: configString},
+}
+
+// configOptionPrefixes are the families of options of the tracing and global integrations, which are accepted as
+// strings.
+var configOptionPrefixes = []string{"datadog-", "global-auth-", "global-rate-limit-", "jaeger-", "otel-", "otlp-", "opentelemetry-", "zipkin-"}
+
+var configSizeRegexp = regexp.MustCompile(`^[0-9]+[kKmMgG]?$`)
+
+// validateConfigMapData validates the values of the known options of the ingress-nginx ConfigMap. The unknown options,
+// e.g. the ones of newer versions of ingress-nginx, are passed as is and reported by UnknownConfigMapKeys.
+// The keys rendered from the typed config cannot be set again.
+func validateConfigMapData(data map[string]string, config *Config, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	rendered := config.ConfigMapData()
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := data[key]
+		if _, ok := rendered[key]; ok {
+			errs = append(errs, field.Forbidden(path.Key(key), "is already set by spec.config"))
+			continue
+		}
+		option, ok := configOptions[key]
+		if !ok {
+			continue
+		}
+		if msg := validateConfigValue(option.valueType, value); msg != "" {
+			errs = append(errs, field.Invalid(path.Key(key), value, msg))
+		}
+	}
+
+	return errs
+}
+
+// validateConfig validates the values of the typed config that the ConfigMap options do not check.
+func validateConfig(config *Config, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if config == nil {
+		return errs
+	}
+	for i, cidr := range config.ProxyRealIPCIDR {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, field.Invalid(path.Child("proxyRealIPCIDR").Index(i), cidr, "must be a CIDR, e.g. 10.0.0.0/8"))
+		}
+	}
+	return errs
+}
+
+// UnknownConfigMapKeys returns the sorted keys of the ingress-nginx ConfigMap that are not known options.
+func UnknownConfigMapKeys(data map[string]string) []string {
+	var keys []string
+	for key := range data {
+		if _, ok := configOptions[key]; !ok && !hasConfigOptionPrefix(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hasConfigOptionPrefix(key string) bool {
+	for _, prefix := range configOptionPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateConfigValue returns why the value is not of the type, or an empty string.
+func validateConfigValue(valueType configValueType, value string) string {
+	switch valueType {
+	case configBool:
+		// ingress-nginx decodes the ConfigMap with weakly typed input, which accepts the values of strconv.ParseBool.
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "must be a boolean, e.g. true or false"
+		}
+	case configInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "must be an integer"
+		}
+	case configSize:
+		if !configSizeRegexp.MatchString(value) {
+			return "must be a size, e.g. 8k or 1m"
+		}
+	case configIntList:
+		for _, item := range strings.Split(value, ",") {
+			if _, err := strconv.Atoi(strings.TrimSpace(item)); err != nil {
+				return "must be a comma-separated list of integers"
+			}
+		}
+	case configWorkerProcesses:
+		if _, err := strconv.Atoi(value); err != nil && value != "auto" {
+			return "must be an integer or auto"
+		}
+	}
+	return ""
+}
+
+// ConfigMapData renders the options of the Config into ingress-nginx ConfigMap keys.
+func (c *Config) ConfigMapData() map[string]string {
+	data := map[string]string{}
+	if c == nil {
+		return data
+	}
+	setInt := func(key string, value *int32) {
+		if value != nil {
+			data[key] = strconv.Itoa(int(*value))
+		}
+	}
+	setBool := func(key string, value *bool) {
+		if value != nil {
+			data[key] = strconv.FormatBool(*value)
+		}
+	}
+	setString := func(key, value string) {
+		if value != "" {
+			data[key] = value
+		}
+	}
+
+	setInt("proxy-connect-timeout", c.ProxyConnectTimeout)
+	setInt("proxy-read-timeout", c.ProxyReadTimeout)
+	setInt("proxy-send-timeout", c.ProxySendTimeout)
+	setString("proxy-body-size", c.ProxyBodySize)
+	if len(c.SSLProtocols) > 0 {
+		protocols := make([]string, len(c.SSLProtocols))
+		for i, protocol := range c.SSLProtocols {
+			protocols[i] = string(protocol)
+		}
+		data["ssl-protocols"] = strings.Join(protocols, " ")
+	}
+	setString("ssl-ciphers", c.SSLCiphers)
+	if c.HSTS != nil {
+		data["hsts"] = strconv.FormatBool(c.HSTS.Enable)
+		if c.HSTS.MaxAge != nil {
+			data["hsts-max-age"] = strconv.FormatInt(*c.HSTS.MaxAge, 10)
+		}
+		setBool("hsts-include-subdomains", c.HSTS.IncludeSubdomains)
+		setBool("hsts-preload", c.HSTS.Preload)
+	}
+	setBool("use-forwarded-headers", c.UseForwardedHeaders)
+	setBool("enable-real-ip", c.EnableRealIP)
+	setString("forwarded-for-header", c.ForwardedForHeader)
+	if len(c.ProxyRealIPCIDR) > 0 {
+		data["proxy-real-ip-cidr"] = strings.Join(c.ProxyRealIPCIDR, ",")
+	}
+	setString("log-format-upstream", c.LogFormatUpstream)
+	setBool("log-format-escape-json", c.LogFormatEscapeJSON)
+
+	return data
+}
+
+// ConfigMapKeyRequiresRestart returns true if the Ingress Controller pods must be restarted to apply a change of the
+// ConfigMap key. ingress-nginx reloads NGINX for the other keys.
+func ConfigMapKeyRequiresRestart(key string) bool {
+	return configOptions[key].restart
+}
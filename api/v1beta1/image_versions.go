@@ -0,0 +1,92 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// kubernetesVersionRange is the range of Kubernetes minor versions supported by an ingress-nginx minor version, with
+// the latest release of that minor version.
+type kubernetesVersionRange struct {
+	min, max, latest string
+}
+
+// supportedKubernetesVersions is the version matrix of ingress-nginx, indexed by its minor version.
+// See https://github.com/kubernetes/ingress-nginx#supported-versions-table.
+var supportedKubernetesVersions = map[string]kubernetesVersionRange{
+	"1.0":  {min: "1.19", max: "1.22", latest: "v1.0.5"},
+	"1.1":  {min: "1.19", max: "1.22", latest: "v1.1.3"},
+	"1.2":  {min: "1.19", max: "1.23", latest: "v1.2.1"},
+	"1.3":  {min: "1.20", max: "1.24", latest: "v1.3.1"},
+	"1.4":  {min: "1.22", max: "1.25", latest: "v1.4.0"},
+	"1.5":  {min: "1.23", max: "1.25", latest: "v1.5.1"},
+	"1.6":  {min: "1.23", max: "1.26", latest: "v1.6.4"},
+	"1.7":  {min: "1.24", max: "1.26", latest: "v1.7.1"},
+	"1.8":  {min: "1.24", max: "1.27", latest: "v1.8.5"},
+	"1.9":  {min: "1.25", max: "1.28", latest: "v1.9.6"},
+	"1.10": {min: "1.26", max: "1.29", latest: "v1.10.6"},
+	"1.11": {min: "1.26", max: "1.30", latest: "v1.11.5"},
+	"1.12": {min: "1.28", max: "1.32", latest: "v1.12.1"},
+}
+
+// supports returns true if the range includes the minor version of Kubernetes.
+func (r kubernetesVersionRange) supports(kubernetesVersion *version.Version) bool {
+	cluster := version.MustParseGeneric(fmt.Sprintf("%d.%d", kubernetesVersion.Major(), kubernetesVersion.Minor()))
+	return cluster.AtLeast(version.MustParseGeneric(r.min)) && !version.MustParseGeneric(r.max).LessThan(cluster)
+}
+
+// CheckImageTag returns an error if the ingress-nginx version of the tag does not support the version of Kubernetes.
+// Tags that are not versions, versions missing from the matrix and an unknown version of Kubernetes are not checked.
+func CheckImageTag(tag string, kubernetesVersion *version.Version) error {
+	if kubernetesVersion == nil {
+		return nil
+	}
+	v, err := version.ParseGeneric(tag)
+	if err != nil {
+		return nil
+	}
+	supported, ok := supportedKubernetesVersions[fmt.Sprintf("%d.%d", v.Major(), v.Minor())]
+	if !ok || supported.supports(kubernetesVersion) {
+		return nil
+	}
+	return fmt.Errorf("ingress-nginx %s supports Kubernetes %s to %s but the cluster runs %s, set spec.upgrade.skipVersionCheck to skip the check",
+		tag, supported.min, supported.max, kubernetesVersion)
+}
+
+// DefaultImageTag returns the latest release of the newest ingress-nginx version that supports the version of
+// Kubernetes, or IngressNginxImageTag if the version of Kubernetes is unknown or no ingress-nginx version supports it.
+func DefaultImageTag(kubernetesVersion *version.Version) string {
+	if kubernetesVersion == nil {
+		return IngressNginxImageTag
+	}
+	minors := make([]*version.Version, 0, len(supportedKubernetesVersions))
+	for minor := range supportedKubernetesVersions {
+		minors = append(minors, version.MustParseGeneric(minor))
+	}
+	sort.Slice(minors, func(i, j int) bool { return minors[j].LessThan(minors[i]) })
+	for _, minor := range minors {
+		supported := supportedKubernetesVersions[fmt.Sprintf("%d.%d", minor.Major(), minor.Minor())]
+		if supported.supports(kubernetesVersion) {
+			return supported.latest
+		}
+	}
+	return IngressNginxImageTag
+}
@@ -17,22 +17,52 @@ limitations under the License.
 package v1beta1
 
 import (
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // NginxIngressControllerSpec defines the desired state of NginxIngressController
 type NginxIngressControllerSpec struct {
-	// The image of the Ingress Controller.
+	// The image of the Ingress Controller. The default is registry.k8s.io/ingress-nginx/controller with the latest
+	// release of the newest ingress-nginx version that supports the version of Kubernetes when the tag is not set.
+	// An image that does not support the version of Kubernetes is rejected, and is not rolled out: the Operator
+	// keeps the running image and reports it in the ImageSupported condition.
 	// +optional
 	Image Image `json:"image"`
 	// The number of replicas of the Ingress Controller pod. The default is 1. Only applies if the type is set to deployment.
 	// +optional
 	// +nullable
 	Replicas *int32 `json:"replicas"`
+	// How the Ingress Controller pods are upgraded when their image or configuration changes.
+	// +optional
+	// +nullable
+	Upgrade *Upgrade `json:"upgrade,omitempty"`
+	// A canary Deployment running a candidate image next to the Ingress Controller pods, behind the same Service.
+	// Remove it to abort the canary, or set the image of the spec to the candidate image to promote it.
+	// Only applies if the workload kind is set to Deployment.
+	// +optional
+	// +nullable
+	Canary *Canary `json:"canary,omitempty"`
+	// The autoscaling of the Ingress Controller pods. Only applies if the workload kind is set to Deployment.
+	// While it is enabled, replicas is only used when the Deployment is created.
+	// +optional
+	// +nullable
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+	// The PodDisruptionBudget of the Ingress Controller pods. No PodDisruptionBudget is created if it is not set.
+	// +optional
+	// +nullable
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
 	// A class of the Ingress controller. The Ingress controller only processes Ingress resources that belong to its class.
 	// +optional
 	IngressClass string `json:"ingressClass"`
+	// The IngressClass of the Ingress controller: whether it is the default class, its labels and its parameters.
+	// +optional
+	// +nullable
+	IngressClassSpec *IngressClassSpec `json:"ingressClassSpec,omitempty"`
 	// The service of the Ingress controller.
 	// +optional
 	// +nullable
@@ -41,12 +71,72 @@ type NginxIngressControllerSpec struct {
 	// +optional
 	// +nullable
 	Workload *Workload `json:"workload"`
+	// The Prometheus metrics of the Ingress controller.
+	// +optional
+	// +nullable
+	Metrics *Metrics `json:"metrics,omitempty"`
+	// The validating admission webhook of the Ingress controller, which rejects invalid Ingress resources.
+	// +optional
+	// +nullable
+	AdmissionWebhook *AdmissionWebhook `json:"admissionWebhook,omitempty"`
+	// The certificate of the HTTPS server for the requests that match no Ingress TLS host. By default ingress-nginx
+	// uses its own fake certificate.
+	// +optional
+	// +nullable
+	DefaultTLS *DefaultTLS `json:"defaultTLS,omitempty"`
+	// The backend of the requests that match no Ingress rule. By default ingress-nginx answers 404 itself.
+	// +optional
+	// +nullable
+	DefaultBackend *DefaultBackend `json:"defaultBackend,omitempty"`
+	// Custom error pages served by the managed default backend for the status codes of the pages.
+	// They cannot be used with a default backend Service.
+	// +optional
+	// +nullable
+	CustomErrorPages *CustomErrorPages `json:"customErrorPages,omitempty"`
+	// GeoIP2 databases of the Ingress Controller, downloaded with a MaxMind license key or mounted from a volume.
+	// Enables use-geoip2 in the ConfigMap.
+	// +optional
+	// +nullable
+	GeoIP *GeoIP `json:"geoip,omitempty"`
+	// TCP ports of the Ingress Controller that proxy to Services. The ports are added to the pods and the Service.
+	// +optional
+	// +listType=map
+	// +listMapKey=port
+	TCPServices []ProxiedService `json:"tcpServices,omitempty"`
+	// UDP ports of the Ingress Controller that proxy to Services. The ports are added to the pods and the Service.
+	// +optional
+	// +listType=map
+	// +listMapKey=port
+	UDPServices []ProxiedService `json:"udpServices,omitempty"`
 	// Namespace to watch for Ingress resources. By default the Ingress controller watches all namespaces.
+	// When a single namespace is watched, the Ingress controller is only granted namespaced permissions in its own
+	// namespace and in the watched namespace, plus read access to the IngressClasses.
 	// +optional
 	WatchNamespace string `json:"watchNamespace"`
+	// Namespaces to watch for Ingress resources, in addition to watchNamespace. Several namespaces are watched
+	// through a namespace selector on their name, which requires cluster-wide permissions.
+	// +optional
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+	// Label selector of the namespaces to watch for Ingress resources. Cannot be used with watchNamespace or
+	// watchNamespaces. The Ingress controller keeps cluster-wide permissions, as the selected namespaces may change.
+	// +optional
+	// +nullable
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Extra command-line flags of the Ingress Controller, e.g. enable-ssl-passthrough: "". The flags are given
+	// without or with their leading dashes, an empty value sets a flag without value. The flags managed by the
+	// Operator, e.g. configmap or election-id, cannot be set.
+	// +optional
+	ExtraArgs map[string]string `json:"extraArgs,omitempty"`
+	// The common options of the Ingress Controller ConfigMap. They are merged with configMapData, which cannot set
+	// the same options.
+	// +optional
+	// +nullable
+	Config *Config `json:"config,omitempty"`
 	// Initial values of the Ingress Controller ConfigMap.
 	// Check https://kubernetes.github.io/ingress-nginx/user-guide/nginx-configuration/configmap/ for
-	// more information about possible values.
+	// more information about possible values. The values of the known options are validated, the unknown options are
+	// passed as is and reported in the ConfigValid condition.
+	// Changing an option that ingress-nginx only reads at startup, e.g. lua-shared-dicts, restarts the pods.
 	// +optional
 	// +nullable
 	ConfigMapData map[string]string `json:"configMapData,omitempty"`
@@ -65,6 +155,15 @@ type Image struct {
 	PullPolicy corev1.PullPolicy `json:"pullPolicy"`
 }
 
+// The default image of the Ingress Controller. The default tag is chosen when the workload is rendered, so that it can
+// change with the Operator: it is the latest release of the newest ingress-nginx version that supports the version of
+// Kubernetes, see DefaultImageTag.
+const (
+	IngressNginxImageRepository = "registry.k8s.io/ingress-nginx/controller"
+	// IngressNginxImageTag is the default tag when the version of Kubernetes is unknown or not supported.
+	IngressNginxImageTag = "v1.12.1"
+)
+
 // Service defines the Service for the Ingress Controller.
 type Service struct {
 	// The type of the Service for the Ingress Controller. Valid Service types are: NodePort and LoadBalancer.
@@ -84,8 +183,43 @@ type Service struct {
 	Ports []corev1.ServicePort `json:"ports"`
 }
 
+// Workload kinds of the Ingress controller.
+const (
+	WorkloadKindDeployment = "Deployment"
+	WorkloadKindDaemonSet  = "DaemonSet"
+)
+
+// Security presets of the pods of the Ingress controller.
+const (
+	// SecurityPresetDefault runs nginx as user 101 on ports 80 and 443 with the NET_BIND_SERVICE capability.
+	SecurityPresetDefault = "Default"
+	// SecurityPresetRestricted complies with the restricted Pod Security Standard: nginx listens on ports 8080 and
+	// 10443 without privilege escalation, with the RuntimeDefault seccomp profile and a read-only root filesystem.
+	// The TCP and UDP services cannot use the privileged ports.
+	SecurityPresetRestricted = "Restricted"
+)
+
+// Ports of the Ingress controller pods.
+const (
+	// HTTPPort and HTTPSPort are the ports nginx listens on with the Default security preset.
+	HTTPPort  = 80
+	HTTPSPort = 443
+	// RestrictedHTTPPort and RestrictedHTTPSPort are the unprivileged ports nginx listens on with the Restricted
+	// security preset. The HTTPS port is not 8443, the default port of the admission webhook.
+	RestrictedHTTPPort  = 8080
+	RestrictedHTTPSPort = 10443
+	// DefaultMetricsPort is the default port of the status server, which also serves the metrics.
+	DefaultMetricsPort = 10254
+	// DefaultAdmissionWebhookPort is the default port of the webhook server.
+	DefaultAdmissionWebhookPort = 8443
+)
+
 // Workload of the Ingress controller.
 type Workload struct {
+	// The kind of the workload of the Ingress controller. Valid kinds are: Deployment and DaemonSet. The default is Deployment.
+	// +kubebuilder:validation:Enum=Deployment;DaemonSet
+	// +optional
+	Kind string `json:"kind,omitempty"`
 	// Specifies resource request and limit of the nginx container
 	// +optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
@@ -93,6 +227,399 @@ type Workload struct {
 	// +optional
 	// +nullable
 	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+	// Specifies annotations of the pods of nginx.
+	// +optional
+	// +nullable
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+	// Specifies the node selector of the pods of nginx.
+	// +optional
+	// +nullable
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Specifies the tolerations of the pods of nginx.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Specifies the affinity and anti-affinity of the pods of nginx.
+	// +optional
+	// +nullable
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Specifies the topology spread constraints of the pods of nginx.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// Specifies the priority class of the pods of nginx.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Use the host's network namespace for the pods of nginx.
+	// +optional
+	HostNetwork bool `json:"hostNetwork,omitempty"`
+	// Specifies the DNS policy of the pods of nginx. The default is ClusterFirst, or ClusterFirstWithHostNet if hostNetwork is set.
+	// +optional
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+	// Specifies extra containers of the pods of nginx, e.g. log shippers.
+	// +optional
+	ExtraContainers []corev1.Container `json:"extraContainers,omitempty"`
+	// Specifies init containers of the pods of nginx, e.g. to download GeoIP databases or plugins.
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+	// Specifies extra volumes of the pods of nginx.
+	// +optional
+	ExtraVolumes []corev1.Volume `json:"extraVolumes,omitempty"`
+	// Specifies extra volume mounts of the nginx container, e.g. custom Lua plugins or NGINX templates.
+	// +optional
+	ExtraVolumeMounts []corev1.VolumeMount `json:"extraVolumeMounts,omitempty"`
+	// Specifies extra environment variables of the nginx container. POD_NAME, POD_NAMESPACE and LD_PRELOAD are set by the Operator.
+	// +optional
+	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+	// The security preset of the pods of nginx. Valid presets are: Default and Restricted. The default is Default.
+	// The Restricted preset complies with the restricted Pod Security Standard and cannot be used with hostNetwork;
+	// the target ports of the Service follow the unprivileged ports 8080 and 10443 of nginx, and the TCP and UDP
+	// services must use ports from 1024.
+	// +kubebuilder:validation:Enum=Default;Restricted
+	// +optional
+	SecurityPreset string `json:"securityPreset,omitempty"`
+	// Specifies the security context of the nginx container. It replaces the one of the security preset.
+	// +optional
+	// +nullable
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+	// Specifies the security context of the pods of nginx. It replaces the one of the security preset.
+	// +optional
+	// +nullable
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// Specifies the liveness probe of the nginx container. The default checks /healthz on the metrics port after 10s
+	// and fails after 5 attempts. The unset fields keep their default values, and a probe without handler checks
+	// /healthz on the metrics port.
+	// +optional
+	// +nullable
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+	// Specifies the readiness probe of the nginx container. The default checks /healthz on the metrics port after 10s
+	// and fails after 3 attempts. The unset fields keep their default values, and a probe without handler checks
+	// /healthz on the metrics port.
+	// +optional
+	// +nullable
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
+	// Specifies the startup probe of the nginx container, e.g. for large configurations that take long to load.
+	// There is no startup probe by default. A probe without handler checks /healthz on the metrics port.
+	// +optional
+	// +nullable
+	StartupProbe *corev1.Probe `json:"startupProbe,omitempty"`
+}
+
+// Autoscaling defines the HorizontalPodAutoscaler of the Ingress Controller.
+type Autoscaling struct {
+	// Enable the HorizontalPodAutoscaler.
+	Enable bool `json:"enable"`
+	// The lower limit for the number of replicas. The default is 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// +nullable
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// The upper limit for the number of replicas.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// The target average CPU utilization, in percentage of the requested CPU.
+	// The default is 50 if neither a target nor metrics are set.
+	// +optional
+	// +nullable
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	// The target average memory utilization, in percentage of the requested memory.
+	// +optional
+	// +nullable
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+	// Extra metrics used to compute the desired replica count, e.g. requests per second of the pods.
+	// +optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+	// The scaling behavior in both up and down directions.
+	// +optional
+	// +nullable
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+}
+
+// Upgrade defines the rollout strategy of the Ingress Controller pods and what to do when an upgrade fails.
+type Upgrade struct {
+	// The maximum number or percentage of pods created above the desired number during a rollout.
+	// The default is 25% for the Deployment workload kind and 0 for the DaemonSet workload kind.
+	// +optional
+	// +nullable
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+	// The maximum number or percentage of pods that can be unavailable during a rollout.
+	// The default is 25% for the Deployment workload kind and 1 for the DaemonSet workload kind.
+	// +optional
+	// +nullable
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// The minimum number of seconds a new pod must be ready before it is considered available.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+	// The number of seconds the new pods have to become ready before the upgrade is considered failed.
+	// The default is 600. Only applies to the Deployment workload kind.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// +nullable
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+	// Roll back to the last successful image when an upgrade to a new image fails. By default the rollout is
+	// paused instead. In both cases the upgrade is retried when the image of the spec changes.
+	// Only applies to the Deployment workload kind.
+	// +optional
+	AutoRollback bool `json:"autoRollback,omitempty"`
+	// Skip the check of the image version against the Kubernetes versions supported by ingress-nginx.
+	// +optional
+	SkipVersionCheck bool `json:"skipVersionCheck,omitempty"`
+}
+
+// Canary defines the canary Deployment of the Ingress Controller. The canary pods receive their share of the traffic of
+// the Service, but the HorizontalPodAutoscaler, the PodDisruptionBudget and the metrics Service leave them out.
+type Canary struct {
+	// The candidate image. The repository and the pull policy default to the ones of the Ingress Controller image.
+	Image Image `json:"image"`
+	// The number of canary replicas as a percentage of the replicas of the Ingress Controller, rounded up.
+	// There is at least one canary replica. The default is 10.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	// +nullable
+	ReplicaPercentage *int32 `json:"replicaPercentage,omitempty"`
+}
+
+// PodDisruptionBudget defines the PodDisruptionBudget of the Ingress Controller. Only one of minAvailable and maxUnavailable can be set.
+type PodDisruptionBudget struct {
+	// The number or percentage of pods that must still be available after an eviction.
+	// +optional
+	// +nullable
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// The number or percentage of pods that can be unavailable after an eviction.
+	// +optional
+	// +nullable
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// Config defines the common options of the ingress-nginx ConfigMap.
+type Config struct {
+	// The timeout in seconds for establishing a connection with an upstream server (proxy-connect-timeout).
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// +nullable
+	ProxyConnectTimeout *int32 `json:"proxyConnectTimeout,omitempty"`
+	// The timeout in seconds for reading a response from an upstream server (proxy-read-timeout).
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// +nullable
+	ProxyReadTimeout *int32 `json:"proxyReadTimeout,omitempty"`
+	// The timeout in seconds for transmitting a request to an upstream server (proxy-send-timeout).
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// +nullable
+	ProxySendTimeout *int32 `json:"proxySendTimeout,omitempty"`
+	// The maximum size of a client request body, e.g. 8m (proxy-body-size). 0 disables the check.
+	// +kubebuilder:validation:Pattern=`^[0-9]+[kKmMgG]?$`
+	// +optional
+	ProxyBodySize string `json:"proxyBodySize,omitempty"`
+	// The enabled SSL protocols (ssl-protocols).
+	// +optional
+	SSLProtocols []SSLProtocol `json:"sslProtocols,omitempty"`
+	// The enabled ciphers, in the OpenSSL format (ssl-ciphers).
+	// +optional
+	SSLCiphers string `json:"sslCiphers,omitempty"`
+	// The HTTP Strict Transport Security header of the HTTPS responses.
+	// +optional
+	// +nullable
+	HSTS *HSTS `json:"hsts,omitempty"`
+	// Pass the X-Forwarded-* headers of the clients to the upstream servers, e.g. behind another L7 proxy
+	// (use-forwarded-headers).
+	// +optional
+	// +nullable
+	UseForwardedHeaders *bool `json:"useForwardedHeaders,omitempty"`
+	// Take the client address from a request header (enable-real-ip).
+	// +optional
+	// +nullable
+	EnableRealIP *bool `json:"enableRealIP,omitempty"`
+	// The header with the client address when useForwardedHeaders or enableRealIP is set (forwarded-for-header).
+	// +optional
+	ForwardedForHeader string `json:"forwardedForHeader,omitempty"`
+	// The CIDRs of the trusted proxies that set the client address (proxy-real-ip-cidr).
+	// +optional
+	ProxyRealIPCIDR []string `json:"proxyRealIPCIDR,omitempty"`
+	// The NGINX log format of the HTTP requests (log-format-upstream).
+	// +optional
+	LogFormatUpstream string `json:"logFormatUpstream,omitempty"`
+	// Escape the variables of the log format for JSON (log-format-escape-json).
+	// +optional
+	// +nullable
+	LogFormatEscapeJSON *bool `json:"logFormatEscapeJSON,omitempty"`
+}
+
+// SSLProtocol is an SSL protocol of NGINX.
+// +kubebuilder:validation:Enum=TLSv1;TLSv1.1;TLSv1.2;TLSv1.3
+type SSLProtocol string
+
+// HSTS defines the HTTP Strict Transport Security header.
+type HSTS struct {
+	// Enable the header (hsts).
+	Enable bool `json:"enable"`
+	// The time in seconds the browsers only use HTTPS (hsts-max-age).
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// +nullable
+	MaxAge *int64 `json:"maxAge,omitempty"`
+	// Apply the header to the subdomains (hsts-include-subdomains).
+	// +optional
+	// +nullable
+	IncludeSubdomains *bool `json:"includeSubdomains,omitempty"`
+	// Ask the browsers to preload the domains (hsts-preload).
+	// +optional
+	// +nullable
+	Preload *bool `json:"preload,omitempty"`
+}
+
+// DefaultTLS defines the default certificate of the Ingress Controller.
+type DefaultTLS struct {
+	// The name of a kubernetes.io/tls Secret in the namespace of the NginxIngressController. When it is not set, the
+	// Operator generates a self-signed certificate in the Secret <name>-default-tls.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// The DNS names of the generated certificate. The default is the DNS names of the Service of the Ingress Controller.
+	// +optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+}
+
+// DefaultBackend defines the default backend of the Ingress Controller: either an existing Service or a
+// default-backend Deployment managed by the Operator.
+type DefaultBackend struct {
+	// An existing Service to use as the default backend. The first port of the Service is used.
+	// +optional
+	// +nullable
+	Service *ServiceReference `json:"service,omitempty"`
+	// The image of the managed default backend. The default is registry.k8s.io/defaultbackend-amd64:1.5, or
+	// registry.k8s.io/ingress-nginx/nginx-errors:v20230505 with custom error pages, and follows the custom error
+	// pages when they are added or removed. The image must serve HTTP on port 8080 and /healthz, and serve the
+	// error pages from /www.
+	// +optional
+	Image Image `json:"image,omitempty"`
+	// The number of replicas of the managed default backend. The default is 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// +nullable
+	Replicas *int32 `json:"replicas,omitempty"`
+	// The resources of the managed default backend pods.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// The default images of the managed default backend, chosen when it is rendered.
+const (
+	// DefaultBackendImageRepository only serves 404 responses.
+	DefaultBackendImageRepository = "registry.k8s.io/defaultbackend-amd64"
+	DefaultBackendImageTag        = "1.5"
+	// ErrorPagesImageRepository serves the custom error pages.
+	ErrorPagesImageRepository = "registry.k8s.io/ingress-nginx/nginx-errors"
+	ErrorPagesImageTag        = "v20230505"
+)
+
+// CustomErrorPages defines the custom error pages of the Ingress Controller.
+type CustomErrorPages struct {
+	// The HTML pages indexed by HTTP status code, e.g. 404 or 503. ingress-nginx sends the responses with these
+	// status codes to the managed default backend, which serves the pages.
+	Pages map[string]string `json:"pages"`
+}
+
+// GeoIP defines the GeoIP2 databases of the Ingress Controller: either a MaxMind license key to download them at
+// startup or a volume with prebuilt databases.
+type GeoIP struct {
+	// The key of a Secret in the namespace of the NginxIngressController holding the MaxMind license key.
+	// The Secret must exist before the pods are rolled out.
+	// +optional
+	// +nullable
+	LicenseKeySecret *corev1.SecretKeySelector `json:"licenseKeySecret,omitempty"`
+	// The MaxMind editions to download with the license key. The default of ingress-nginx is GeoLite2-City and
+	// GeoLite2-ASN.
+	// +optional
+	EditionIDs []string `json:"editionIDs,omitempty"`
+	// A volume with prebuilt databases, e.g. GeoLite2-City.mmdb, mounted where ingress-nginx reads them.
+	// +optional
+	// +nullable
+	Databases *GeoIPDatabases `json:"databases,omitempty"`
+}
+
+// GeoIPDatabases defines the volume with the prebuilt GeoIP2 databases. Exactly one source must be set.
+type GeoIPDatabases struct {
+	// The name of a PersistentVolumeClaim in the namespace of the NginxIngressController.
+	// +optional
+	PersistentVolumeClaim string `json:"persistentVolumeClaim,omitempty"`
+	// The name of a ConfigMap in the namespace of the NginxIngressController, with the databases as binaryData.
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+}
+
+// ServiceReference references a Service.
+type ServiceReference struct {
+	// The namespace of the Service. The default is the namespace of the NginxIngressController.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// The name of the Service.
+	Name string `json:"name"`
+}
+
+// ProxiedService defines a TCP or UDP port of the Ingress Controller that proxies to a Service.
+type ProxiedService struct {
+	// The port exposed by the Ingress Controller pods and Service.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+	// The namespace of the backend Service. The default is the namespace of the NginxIngressController.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// The name of the backend Service.
+	ServiceName string `json:"serviceName"`
+	// The port of the backend Service, as a number or a name.
+	ServicePort intstr.IntOrString `json:"servicePort"`
+}
+
+// IngressClassSpec defines the IngressClass of the Ingress Controller.
+type IngressClassSpec struct {
+	// Mark the IngressClass as the default class of the cluster, used by the Ingress resources without a class.
+	// Only one NginxIngressController can be the default: while an older one is the default, the IngressClass is not
+	// marked and the DefaultIngressClass condition reports the conflict.
+	// +optional
+	Default bool `json:"default,omitempty"`
+	// Extra labels of the IngressClass.
+	// +optional
+	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+	// A reference to a resource with additional configuration of the IngressClass.
+	// +optional
+	// +nullable
+	Parameters *networkingv1.IngressClassParametersReference `json:"parameters,omitempty"`
+}
+
+// AdmissionWebhook defines the validating admission webhook of the Ingress Controller.
+type AdmissionWebhook struct {
+	// Enable the validating admission webhook.
+	Enabled bool `json:"enabled"`
+	// The port of the webhook server in the Ingress Controller pods. The default is 8443.
+	// +kubebuilder:validation:Minimum=1024
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	// +nullable
+	Port *int32 `json:"port,omitempty"`
+	// How to handle the Ingress resources when the webhook is unavailable. Valid values are: Fail and Ignore.
+	// From Kubernetes 1.28 the webhook only receives the Ingress resources of the ingress class and the default is Fail.
+	// Before, it receives the Ingress resources of all the classes in the watched namespaces, so the default is Ignore
+	// to keep an unavailable Ingress Controller from blocking the other classes.
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	// +optional
+	// +nullable
+	FailurePolicy *admissionregistrationv1.FailurePolicyType `json:"failurePolicy,omitempty"`
+	// Issue the webhook certificate with cert-manager. By default the Operator generates a self-signed certificate.
+	// +optional
+	// +nullable
+	CertManager *CertManager `json:"certManager,omitempty"`
+}
+
+// CertManager defines the cert-manager issuer of a certificate.
+type CertManager struct {
+	// The name of the issuer.
+	IssuerName string `json:"issuerName"`
+	// The kind of the issuer. Valid kinds are: Issuer and ClusterIssuer. The default is Issuer.
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +optional
+	IssuerKind string `json:"issuerKind,omitempty"`
 }
 
 // Metrics defines the Metrics metrics for the Ingress Controller.
@@ -106,16 +633,138 @@ type Metrics struct {
 	// +optional
 	// +nullable
 	Port *uint16 `json:"port"`
+	// Export metrics per-host. Disable it to reduce the cardinality of the metrics.
+	// +optional
+	PerHost *bool `json:"perHost,omitempty"`
+	// The ServiceMonitor of the metrics. Only created if the prometheus-operator CRDs are installed in the cluster.
+	// +optional
+	// +nullable
+	ServiceMonitor *ServiceMonitor `json:"serviceMonitor,omitempty"`
 }
 
+// ServiceMonitor defines the prometheus-operator ServiceMonitor for the Ingress Controller metrics.
+type ServiceMonitor struct {
+	// Create a ServiceMonitor for the metrics Service.
+	Enable bool `json:"enable"`
+	// Specifies extra labels of the ServiceMonitor, e.g. the labels selected by the Prometheus instance.
+	// +optional
+	// +nullable
+	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+	// Interval at which metrics should be scraped, e.g. 30s. Defaults to the Prometheus global scrape interval.
+	// +optional
+	Interval string `json:"interval,omitempty"`
+}
+
+// Condition types of the NginxIngressController.
+const (
+	// ConditionAvailable means at least one Ingress Controller pod is ready to serve traffic.
+	ConditionAvailable = "Available"
+	// ConditionProgressing means the workload of the Ingress Controller is rolling out.
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded means the Operator failed to reconcile the Ingress Controller or no pod is ready.
+	ConditionDegraded = "Degraded"
+	// ConditionConfigValid means the spec of the NginxIngressController is valid.
+	ConditionConfigValid = "ConfigValid"
+	// ConditionImageSupported means the images of the spec support the version of Kubernetes. Otherwise the Operator
+	// keeps the running images.
+	ConditionImageSupported = "ImageSupported"
+	// ConditionDefaultIngressClass means the IngressClass is the default one, when spec.ingressClassSpec.default is
+	// set. It is false while an older NginxIngressController has the default IngressClass.
+	ConditionDefaultIngressClass = "DefaultIngressClass"
+)
+
 // NginxIngressControllerStatus defines the observed state of NginxIngressController
 type NginxIngressControllerStatus struct {
-	// Deployed is true if the Operator has finished the deployment of the NginxIngressController.
+	// Deployed is true if the Ingress Controller is available.
 	Deployed bool `json:"deployed"`
+	// The generation of the NginxIngressController observed by the Operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// The number of desired Ingress Controller pods.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// The number of ready Ingress Controller pods.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// The image currently used by the workload of the Ingress Controller.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// The progress of the latest upgrade of the image of the Ingress Controller.
+	// +optional
+	// +nullable
+	Upgrade *UpgradeStatus `json:"upgrade,omitempty"`
+	// The state of the canary Deployment, if any.
+	// +optional
+	// +nullable
+	Canary *CanaryStatus `json:"canary,omitempty"`
+	// The load-balancer ingress addresses of the Service of the Ingress Controller.
+	// +optional
+	LoadBalancer []corev1.LoadBalancerIngress `json:"loadBalancer,omitempty"`
+	// The latest available observations of the state of the Ingress Controller.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// UpgradeStatus defines the progress of the latest upgrade of the image of the Ingress Controller.
+type UpgradeStatus struct {
+	// The phase of the upgrade: Progressing, Complete, Paused or RolledBack.
+	Phase string `json:"phase"`
+	// The image the upgrade rolls out.
+	// +optional
+	TargetImage string `json:"targetImage,omitempty"`
+	// The last image that was fully rolled out.
+	// +optional
+	LastSuccessfulImage string `json:"lastSuccessfulImage,omitempty"`
+	// The image whose upgrade failed. It is not retried until the image of the spec changes.
+	// +optional
+	FailedImage string `json:"failedImage,omitempty"`
+	// The number of pods running the target image.
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+	// A human readable message about the upgrade.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// CanaryStatus defines the state of the canary Deployment of the Ingress Controller.
+type CanaryStatus struct {
+	// The candidate image run by the canary pods.
+	Image string `json:"image"`
+	// The number of desired canary pods.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// The number of ready canary pods.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// Healthy is true if all the canary pods are ready.
+	Healthy bool `json:"healthy"`
+	// A human readable message about the canary.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	// UpgradePhaseProgressing means the target image is rolling out.
+	UpgradePhaseProgressing = "Progressing"
+	// UpgradePhaseComplete means all the pods run the target image and are ready.
+	UpgradePhaseComplete = "Complete"
+	// UpgradePhasePaused means the new pods failed to become ready and the rollout is paused.
+	UpgradePhasePaused = "Paused"
+	// UpgradePhaseRolledBack means the new pods failed to become ready and the last successful image is restored.
+	UpgradePhaseRolledBack = "RolledBack"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Class",type=string,JSONPath=`.spec.ingressClass`
+//+kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyReplicas`
+//+kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.status.replicas`
+//+kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+//+kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.status.image`,priority=1
+//+kubebuilder:printcolumn:name="Upgrade",type=string,JSONPath=`.status.upgrade.phase`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // NginxIngressController is the Schema for the nginxingresscontrollers API
 type NginxIngressController struct {
@@ -0,0 +1,644 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/version"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of NginxIngressController.
+// The images are recheck against the version of Kubernetes, unless it is nil.
+func (r *NginxIngressController) SetupWebhookWithManager(mgr ctrl.Manager, kubernetesVersion *version.Version) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&nginxIngressControllerValidator{Client: mgr.GetClient(), KubernetesVersion: kubernetesVersion}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-networking-kubegems-io-v1beta1-nginxingresscontroller,mutating=true,failurePolicy=fail,sideEffects=None,groups=networking.kubegems.io,resources=nginxingresscontrollers,verbs=create;update,versions=v1beta1,name=mnginxingresscontroller.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &NginxIngressController{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type.
+// It is also applied by the Operator before every reconciliation.
+func (r *NginxIngressController) Default() {
+	if r.Spec.Image.Repository == "" {
+		r.Spec.Image.Repository = IngressNginxImageRepository
+	}
+	if r.Spec.Image.PullPolicy == "" {
+		r.Spec.Image.PullPolicy = corev1.PullIfNotPresent
+	}
+
+	if r.Spec.Replicas == nil {
+		var replicas int32 = 1
+		r.Spec.Replicas = &replicas
+	}
+
+	if r.Spec.Service == nil {
+		r.Spec.Service = &Service{}
+	}
+	if r.Spec.Service.Type == "" {
+		r.Spec.Service.Type = string(corev1.ServiceTypeNodePort)
+	}
+
+	if r.Spec.Workload == nil {
+		r.Spec.Workload = &Workload{}
+	}
+	if r.Spec.Workload.Kind == "" {
+		r.Spec.Workload.Kind = WorkloadKindDeployment
+	}
+	if r.Spec.Workload.SecurityPreset == "" {
+		r.Spec.Workload.SecurityPreset = SecurityPresetDefault
+	}
+
+	if r.Spec.IngressClass == "" {
+		r.Spec.IngressClass = "nginx"
+	}
+
+	// Custom error pages are served by the managed default backend. Its image is chosen when it is rendered, as it
+	// depends on the custom error pages.
+	if r.Spec.CustomErrorPages != nil && r.Spec.DefaultBackend == nil {
+		r.Spec.DefaultBackend = &DefaultBackend{}
+	}
+}
+
+// ValidateSpec returns an error if the spec of the NginxIngressController is not valid.
+// The spec is expected to be defaulted.
+func (r *NginxIngressController) ValidateSpec() error {
+	return r.validateSpec().ToAggregate()
+}
+
+func (r *NginxIngressController) validateSpec() field.ErrorList {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	pullPolicies := []string{string(corev1.PullAlways), string(corev1.PullIfNotPresent), string(corev1.PullNever)}
+	if !containsStr(pullPolicies, string(r.Spec.Image.PullPolicy)) {
+		errs = append(errs, field.NotSupported(spec.Child("image", "pullPolicy"), r.Spec.Image.PullPolicy, pullPolicies))
+	}
+
+	if r.Spec.Replicas != nil && *r.Spec.Replicas < 0 {
+		errs = append(errs, field.Invalid(spec.Child("replicas"), *r.Spec.Replicas, "must be greater than or equal to 0"))
+	}
+
+	serviceTypes := []string{string(corev1.ServiceTypeNodePort), string(corev1.ServiceTypeLoadBalancer)}
+	if r.Spec.Service != nil && !containsStr(serviceTypes, r.Spec.Service.Type) {
+		errs = append(errs, field.NotSupported(spec.Child("service", "type"), r.Spec.Service.Type, serviceTypes))
+	}
+
+	workloadKinds := []string{WorkloadKindDeployment, WorkloadKindDaemonSet}
+	if r.Spec.Workload != nil && !containsStr(workloadKinds, r.Spec.Workload.Kind) {
+		errs = append(errs, field.NotSupported(spec.Child("workload", "kind"), r.Spec.Workload.Kind, workloadKinds))
+	}
+
+	if workload := r.Spec.Workload; workload != nil {
+		errs = append(errs, r.validateWorkloadExtensions(workload, spec.Child("workload"))...)
+		errs = append(errs, validateProbes(workload, spec.Child("workload"))...)
+	}
+
+	if as := r.Spec.Autoscaling; as != nil && as.Enable {
+		path := spec.Child("autoscaling")
+		if r.Spec.Workload != nil && r.Spec.Workload.Kind != WorkloadKindDeployment {
+			errs = append(errs, field.Forbidden(path, "autoscaling only applies to the Deployment workload kind"))
+		}
+		if as.MaxReplicas < 1 {
+			errs = append(errs, field.Invalid(path.Child("maxReplicas"), as.MaxReplicas, "must be greater than or equal to 1"))
+		}
+		if as.MinReplicas != nil && *as.MinReplicas > as.MaxReplicas {
+			errs = append(errs, field.Invalid(path.Child("minReplicas"), *as.MinReplicas, "must be less than or equal to maxReplicas"))
+		}
+	}
+
+	if backend := r.Spec.DefaultBackend; backend != nil && backend.Service != nil {
+		path := spec.Child("defaultBackend")
+		if backend.Service.Name == "" {
+			errs = append(errs, field.Required(path.Child("service", "name"), "the name of the Service must be set"))
+		}
+		if backend.Image != (Image{}) || backend.Replicas != nil {
+			errs = append(errs, field.Forbidden(path, "image and replicas only apply to the managed default backend, not to a Service"))
+		}
+	}
+
+	if pages := r.Spec.CustomErrorPages; pages != nil {
+		path := spec.Child("customErrorPages")
+		if r.Spec.DefaultBackend != nil && r.Spec.DefaultBackend.Service != nil {
+			errs = append(errs, field.Forbidden(path, "custom error pages are served by the managed default backend, not by a Service"))
+		}
+		if len(pages.Pages) == 0 {
+			errs = append(errs, field.Required(path.Child("pages"), "at least one page must be set"))
+		}
+		codes := make([]string, 0, len(pages.Pages))
+		for code := range pages.Pages {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			if status, err := strconv.Atoi(code); err != nil || status < 400 || status > 599 {
+				errs = append(errs, field.Invalid(path.Child("pages").Key(code), code, "must be an HTTP error status code between 400 and 599"))
+			}
+		}
+		if backend := r.Spec.DefaultBackend; backend != nil && backend.Image.Repository == DefaultBackendImageRepository {
+			errs = append(errs, field.Forbidden(spec.Child("defaultBackend", "image", "repository"), "the image cannot serve custom error pages"))
+		}
+		if _, ok := r.Spec.ConfigMapData["custom-http-errors"]; ok {
+			errs = append(errs, field.Forbidden(spec.Child("configMapData").Key("custom-http-errors"), "is already set by spec.customErrorPages"))
+		}
+	}
+
+	if geoip := r.Spec.GeoIP; geoip != nil {
+		errs = append(errs, validateGeoIP(geoip, spec.Child("geoip"))...)
+		if _, ok := r.Spec.ConfigMapData["use-geoip2"]; ok {
+			errs = append(errs, field.Forbidden(spec.Child("configMapData").Key("use-geoip2"), "is already set by spec.geoip"))
+		}
+	}
+
+	if canary := r.Spec.Canary; canary != nil {
+		path := spec.Child("canary")
+		if r.Spec.Workload != nil && r.Spec.Workload.Kind != WorkloadKindDeployment {
+			errs = append(errs, field.Forbidden(path, "a canary only applies to the Deployment workload kind"))
+		}
+		if canary.Image.Tag == "" {
+			errs = append(errs, field.Required(path.Child("image", "tag"), "the tag of the candidate image must be set"))
+		}
+		if canary.Image.PullPolicy != "" && !containsStr(pullPolicies, string(canary.Image.PullPolicy)) {
+			errs = append(errs, field.NotSupported(path.Child("image", "pullPolicy"), canary.Image.PullPolicy, pullPolicies))
+		}
+	}
+
+	if pdb := r.Spec.PodDisruptionBudget; pdb != nil {
+		path := spec.Child("podDisruptionBudget")
+		if pdb.MinAvailable != nil && pdb.MaxUnavailable != nil {
+			errs = append(errs, field.Invalid(path, "", "minAvailable and maxUnavailable cannot be both set"))
+		}
+		if pdb.MinAvailable == nil && pdb.MaxUnavailable == nil {
+			errs = append(errs, field.Required(path, "one of minAvailable and maxUnavailable must be set"))
+		}
+	}
+
+	errs = append(errs, r.validateWatchScope(spec)...)
+
+	errs = append(errs, validateExtraArgs(r.Spec.ExtraArgs, spec.Child("extraArgs"))...)
+
+	errs = append(errs, r.validatePorts(spec)...)
+	errs = append(errs, validateProxiedServices(r.Spec.TCPServices, r.reservedTCPPorts(), spec.Child("tcpServices"))...)
+	errs = append(errs, validateProxiedServices(r.Spec.UDPServices, nil, spec.Child("udpServices"))...)
+
+	errs = append(errs, validateConfig(r.Spec.Config, spec.Child("config"))...)
+	errs = append(errs, validateConfigMapData(r.Spec.ConfigMapData, r.Spec.Config, spec.Child("configMapData"))...)
+
+	if webhook := r.Spec.AdmissionWebhook; webhook != nil && webhook.Enabled && webhook.CertManager != nil {
+		if webhook.CertManager.IssuerName == "" {
+			errs = append(errs, field.Required(spec.Child("admissionWebhook", "certManager", "issuerName"), "the issuer of the certificate must be set"))
+		}
+	}
+
+	return errs
+}
+
+// validateWorkloadExtensions validates that the extra containers, volumes and environment variables of the workload
+// do not clash with the ones set by the Operator, including the ones of the security preset.
+func (r *NginxIngressController) validateWorkloadExtensions(workload *Workload, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	containers := map[string]bool{r.Name: true}
+	reservedVolumes := []string{"webhook-cert"}
+	if r.restrictedSecurity() {
+		if workload.HostNetwork {
+			errs = append(errs, field.Forbidden(path.Child("hostNetwork"), "cannot be used with the Restricted security preset"))
+		}
+		// The Restricted preset copies the nginx configuration to a writable volume.
+		containers["copy-nginx-config"] = true
+		reservedVolumes = append(reservedVolumes, "nginx-config", "tmp", "ingress-controller")
+	}
+	for i, container := range workload.InitContainers {
+		if containers[container.Name] {
+			errs = append(errs, field.Duplicate(path.Child("initContainers").Index(i).Child("name"), container.Name))
+		}
+		containers[container.Name] = true
+	}
+	for i, container := range workload.ExtraContainers {
+		if containers[container.Name] {
+			errs = append(errs, field.Duplicate(path.Child("extraContainers").Index(i).Child("name"), container.Name))
+		}
+		containers[container.Name] = true
+	}
+
+	if r.Spec.GeoIP != nil {
+		reservedVolumes = append(reservedVolumes, "geoip")
+	}
+	for i, volume := range workload.ExtraVolumes {
+		if containsStr(reservedVolumes, volume.Name) {
+			errs = append(errs, field.Invalid(path.Child("extraVolumes").Index(i).Child("name"), volume.Name, "is reserved by the Operator"))
+		}
+	}
+
+	reservedEnv := []string{"POD_NAME", "POD_NAMESPACE", "LD_PRELOAD"}
+	if r.Spec.GeoIP != nil && r.Spec.GeoIP.LicenseKeySecret != nil {
+		reservedEnv = append(reservedEnv, "MAXMIND_LICENSE_KEY")
+	}
+	for i, env := range workload.ExtraEnv {
+		if containsStr(reservedEnv, env.Name) {
+			errs = append(errs, field.Invalid(path.Child("extraEnv").Index(i).Child("name"), env.Name, "is set by the Operator"))
+		}
+	}
+
+	return errs
+}
+
+// validateProbes validates the probes of the workload, which are only recheck by the API server when the workload is
+// applied.
+func validateProbes(workload *Workload, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	probes := []struct {
+		name  string
+		probe *corev1.Probe
+	}{
+		{"livenessProbe", workload.LivenessProbe},
+		{"readinessProbe", workload.ReadinessProbe},
+		{"startupProbe", workload.StartupProbe},
+	}
+	for _, p := range probes {
+		if p.probe == nil {
+			continue
+		}
+		if p.name != "readinessProbe" && p.probe.SuccessThreshold > 1 {
+			errs = append(errs, field.Invalid(path.Child(p.name, "successThreshold"), p.probe.SuccessThreshold, "must be 1"))
+		}
+		if p.probe.TerminationGracePeriodSeconds != nil && p.name == "readinessProbe" {
+			errs = append(errs, field.Forbidden(path.Child(p.name, "terminationGracePeriodSeconds"), "cannot be set for a readiness probe"))
+		}
+	}
+	return errs
+}
+
+func validateGeoIP(geoip *GeoIP, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	switch {
+	case geoip.LicenseKeySecret == nil && geoip.Databases == nil:
+		errs = append(errs, field.Required(path, "either licenseKeySecret or databases must be set"))
+	case geoip.LicenseKeySecret != nil && geoip.Databases != nil:
+		errs = append(errs, field.Forbidden(path.Child("databases"), "cannot be set with licenseKeySecret"))
+	}
+
+	if secret := geoip.LicenseKeySecret; secret != nil {
+		if secret.Name == "" {
+			errs = append(errs, field.Required(path.Child("licenseKeySecret", "name"), "the name of the Secret must be set"))
+		}
+		if secret.Key == "" {
+			errs = append(errs, field.Required(path.Child("licenseKeySecret", "key"), "the key of the license key must be set"))
+		}
+	} else if len(geoip.EditionIDs) > 0 {
+		errs = append(errs, field.Forbidden(path.Child("editionIDs"), "the editions are only downloaded with licenseKeySecret"))
+	}
+
+	if databases := geoip.Databases; databases != nil && (databases.PersistentVolumeClaim == "") == (databases.ConfigMap == "") {
+		errs = append(errs, field.Invalid(path.Child("databases"), databases, "exactly one of persistentVolumeClaim or configMap must be set"))
+	}
+
+	return errs
+}
+
+// restrictedSecurity returns true if the pods of nginx use the Restricted security preset.
+func (r *NginxIngressController) restrictedSecurity() bool {
+	return r.Spec.Workload != nil && r.Spec.Workload.SecurityPreset == SecurityPresetRestricted
+}
+
+// nginxPorts returns the HTTP and HTTPS ports nginx listens on, which are unprivileged with the Restricted preset.
+func (r *NginxIngressController) nginxPorts() []int32 {
+	if r.restrictedSecurity() {
+		return []int32{RestrictedHTTPPort, RestrictedHTTPSPort}
+	}
+	return []int32{HTTPPort, HTTPSPort}
+}
+
+func (r *NginxIngressController) metricsPort() int32 {
+	if r.Spec.Metrics != nil && r.Spec.Metrics.Port != nil {
+		return int32(*r.Spec.Metrics.Port)
+	}
+	return DefaultMetricsPort
+}
+
+// reservedTCPPorts returns the TCP ports of the Ingress Controller pods that cannot proxy to a Service.
+func (r *NginxIngressController) reservedTCPPorts() []int32 {
+	ports := append(r.nginxPorts(), r.metricsPort())
+	if webhook := r.Spec.AdmissionWebhook; webhook != nil && webhook.Enabled {
+		port := int32(DefaultAdmissionWebhookPort)
+		if webhook.Port != nil {
+			port = *webhook.Port
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+// validatePorts validates that the metrics and webhook ports do not clash with the ports of nginx, and that nginx
+// can bind the ports of the TCP and UDP services with the Restricted preset.
+func (r *NginxIngressController) validatePorts(spec *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	ports := r.nginxPorts()
+	if r.Spec.Metrics != nil && r.Spec.Metrics.Port != nil && containsInt32(ports, r.metricsPort()) {
+		errs = append(errs, field.Invalid(spec.Child("metrics", "port"), r.metricsPort(), "is already used by nginx"))
+	}
+	ports = append(ports, r.metricsPort())
+	if webhook := r.Spec.AdmissionWebhook; webhook != nil && webhook.Enabled && webhook.Port != nil && containsInt32(ports, *webhook.Port) {
+		errs = append(errs, field.Invalid(spec.Child("admissionWebhook", "port"), *webhook.Port, "is already used by the Ingress Controller"))
+	}
+
+	if r.restrictedSecurity() {
+		for _, proxied := range []struct {
+			name     string
+			services []ProxiedService
+		}{{"tcpServices", r.Spec.TCPServices}, {"udpServices", r.Spec.UDPServices}} {
+			for i, service := range proxied.services {
+				if service.Port < 1024 {
+					errs = append(errs, field.Invalid(spec.Child(proxied.name).Index(i).Child("port"), service.Port,
+						"must be greater than or equal to 1024 with the Restricted security preset"))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func validateProxiedServices(services []ProxiedService, reserved []int32, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := map[int32]bool{}
+	for i, service := range services {
+		path := path.Index(i)
+		for _, port := range reserved {
+			if service.Port == port {
+				errs = append(errs, field.Invalid(path.Child("port"), service.Port, "is already used by the Ingress Controller"))
+			}
+		}
+		if seen[service.Port] {
+			errs = append(errs, field.Duplicate(path.Child("port"), service.Port))
+		}
+		seen[service.Port] = true
+		if service.ServiceName == "" {
+			errs = append(errs, field.Required(path.Child("serviceName"), "the name of the backend Service must be set"))
+		}
+		if service.ServicePort.String() == "" || service.ServicePort.String() == "0" {
+			errs = append(errs, field.Required(path.Child("servicePort"), "the port of the backend Service must be set"))
+		}
+	}
+	return errs
+}
+
+// managedArgs are the flags of the Ingress Controller set by the Operator, with the spec field that sets them if any.
+var managedArgs = map[string]string{
+	"configmap":                      "",
+	"publish-service":                "",
+	"election-id":                    "",
+	"controller-class":               "spec.ingressClass",
+	"ingress-class":                  "spec.ingressClass",
+	"watch-namespace":                "spec.watchNamespace",
+	"watch-namespace-selector":       "spec.namespaceSelector",
+	"default-ssl-certificate":        "spec.defaultTLS",
+	"default-backend-service":        "spec.defaultBackend",
+	"tcp-services-configmap":         "spec.tcpServices",
+	"udp-services-configmap":         "spec.udpServices",
+	"validating-webhook":             "spec.admissionWebhook",
+	"validating-webhook-certificate": "spec.admissionWebhook",
+	"validating-webhook-key":         "spec.admissionWebhook",
+	"enable-metrics":                 "spec.metrics",
+	"metrics-per-host":               "spec.metrics",
+	"healthz-port":                   "spec.metrics",
+	"maxmind-license-key":            "spec.geoip",
+	"maxmind-edition-ids":            "spec.geoip",
+	"http-port":                      "spec.workload.securityPreset",
+	"https-port":                     "spec.workload.securityPreset",
+}
+
+// ExtraArgName returns the name of a flag of extraArgs without its leading dashes.
+func ExtraArgName(flag string) string {
+	return strings.TrimLeft(flag, "-")
+}
+
+func validateExtraArgs(args map[string]string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	flags := make([]string, 0, len(args))
+	for flag := range args {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+
+	seen := map[string]bool{}
+	for _, flag := range flags {
+		name := ExtraArgName(flag)
+		switch {
+		case name == "" || strings.ContainsAny(name, "= "):
+			errs = append(errs, field.Invalid(path.Key(flag), flag, "must be a flag name"))
+		case seen[name]:
+			errs = append(errs, field.Duplicate(path.Key(flag), name))
+		default:
+			if hint, ok := managedArgs[name]; ok {
+				msg := "is managed by the Operator"
+				if hint != "" {
+					msg += ", use " + hint
+				}
+				errs = append(errs, field.Forbidden(path.Key(flag), msg))
+			}
+		}
+		seen[name] = true
+	}
+
+	return errs
+}
+
+// validateWatchScope validates the namespaces watched by the Ingress Controller, given either by name or by label.
+func (r *NginxIngressController) validateWatchScope(spec *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if r.Spec.WatchNamespace != "" {
+		for _, msg := range validation.IsDNS1123Label(r.Spec.WatchNamespace) {
+			errs = append(errs, field.Invalid(spec.Child("watchNamespace"), r.Spec.WatchNamespace, msg))
+		}
+	}
+	seen := map[string]bool{}
+	for i, ns := range r.Spec.WatchNamespaces {
+		path := spec.Child("watchNamespaces").Index(i)
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			errs = append(errs, field.Invalid(path, ns, msg))
+		}
+		if seen[ns] {
+			errs = append(errs, field.Duplicate(path, ns))
+		}
+		seen[ns] = true
+	}
+
+	if r.Spec.NamespaceSelector != nil {
+		path := spec.Child("namespaceSelector")
+		if r.Spec.WatchNamespace != "" || len(r.Spec.WatchNamespaces) > 0 {
+			errs = append(errs, field.Forbidden(path, "cannot be used with watchNamespace or watchNamespaces"))
+		}
+		errs = append(errs, metav1validation.ValidateLabelSelector(r.Spec.NamespaceSelector, path)...)
+	}
+
+	return errs
+}
+
+//+kubebuilder:webhook:path=/validate-networking-kubegems-io-v1beta1-nginxingresscontroller,mutating=false,failurePolicy=fail,sideEffects=None,groups=networking.kubegems.io,resources=nginxingresscontrollers,verbs=create;update,versions=v1beta1,name=vnginxingresscontroller.kb.io,admissionReviewVersions=v1
+
+// nginxIngressControllerValidator validates NginxIngressControllers, including conflicts with the other instances.
+// +kubebuilder:object:generate=false
+type nginxIngressControllerValidator struct {
+	Client            client.Reader
+	KubernetesVersion *version.Version
+}
+
+var _ webhook.CustomValidator = &nginxIngressControllerValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *nginxIngressControllerValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	instance, ok := obj.(*NginxIngressController)
+	if !ok {
+		return fmt.Errorf("expected a NginxIngressController but got a %T", obj)
+	}
+	return v.validate(ctx, instance, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+// The updates that do not change the spec, e.g. of the finalizer, and the updates of an instance being deleted are
+// always allowed, so that an instance that became invalid can still be deleted.
+func (v *nginxIngressControllerValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	instance, ok := newObj.(*NginxIngressController)
+	if !ok {
+		return fmt.Errorf("expected a NginxIngressController but got a %T", newObj)
+	}
+	old, ok := oldObj.(*NginxIngressController)
+	if !ok {
+		return fmt.Errorf("expected a NginxIngressController but got a %T", oldObj)
+	}
+	if instance.DeletionTimestamp != nil || equality.Semantic.DeepEqual(old.Spec, instance.Spec) {
+		return nil
+	}
+	return v.validate(ctx, instance, old)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *nginxIngressControllerValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// validate validates the spec of the instance, and its conflicts with the other instances when it is created or
+// when its IngressClass or default flag changes from the old instance.
+func (v *nginxIngressControllerValidator) validate(ctx context.Context, instance, old *NginxIngressController) error {
+	instance = instance.DeepCopy()
+	instance.Default()
+
+	errs := instance.validateSpec()
+
+	errs = append(errs, v.validateImageVersions(instance, old)...)
+
+	classChanged, defaultChanged := true, true
+	if old != nil {
+		old = old.DeepCopy()
+		old.Default()
+		classChanged = old.Spec.IngressClass != instance.Spec.IngressClass
+		defaultChanged = old.IsDefaultIngressClass() != instance.IsDefaultIngressClass()
+	}
+
+	if classChanged || defaultChanged {
+		list := &NginxIngressControllerList{}
+		if err := v.Client.List(ctx, list); err != nil {
+			return err
+		}
+		for i := range list.Items {
+			other := list.Items[i].DeepCopy()
+			if other.Namespace == instance.Namespace && other.Name == instance.Name {
+				continue
+			}
+			other.Default()
+			if classChanged && other.Spec.IngressClass == instance.Spec.IngressClass {
+				errs = append(errs, field.Duplicate(field.NewPath("spec", "ingressClass"),
+					fmt.Sprintf("%s is already used by %s/%s", instance.Spec.IngressClass, other.Namespace, other.Name)))
+			}
+			if defaultChanged && instance.IsDefaultIngressClass() && other.IsDefaultIngressClass() {
+				errs = append(errs, field.Forbidden(field.NewPath("spec", "ingressClassSpec", "default"),
+					fmt.Sprintf("the default IngressClass is already %s of %s/%s", other.Spec.IngressClass, other.Namespace, other.Name)))
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("NginxIngressController").GroupKind(), instance.Name, errs)
+}
+
+// validateImageVersions validates that the images support the version of Kubernetes when they are set or changed,
+// unless the check is skipped. The default tag always supports it.
+func (v *nginxIngressControllerValidator) validateImageVersions(instance, old *NginxIngressController) field.ErrorList {
+	var errs field.ErrorList
+	if instance.Spec.Upgrade != nil && instance.Spec.Upgrade.SkipVersionCheck {
+		return errs
+	}
+	recheck := old == nil || (old.Spec.Upgrade != nil && old.Spec.Upgrade.SkipVersionCheck)
+	spec := field.NewPath("spec")
+	if tag := instance.Spec.Image.Tag; tag != "" && (recheck || tag != old.Spec.Image.Tag) {
+		if err := CheckImageTag(tag, v.KubernetesVersion); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("image", "tag"), tag, err.Error()))
+		}
+	}
+	if canary := instance.Spec.Canary; canary != nil && (recheck || old.Spec.Canary == nil || canary.Image.Tag != old.Spec.Canary.Image.Tag) {
+		if err := CheckImageTag(canary.Image.Tag, v.KubernetesVersion); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("canary", "image", "tag"), canary.Image.Tag, err.Error()))
+		}
+	}
+	return errs
+}
+
+// IsDefaultIngressClass returns true if the IngressClass of the NginxIngressController is the default class.
+func (r *NginxIngressController) IsDefaultIngressClass() bool {
+	return r.Spec.IngressClassSpec != nil && r.Spec.IngressClassSpec.Default
+}
+
+func containsStr(src []string, dest string) bool {
+	for _, v := range src {
+		if v == dest {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt32(src []int32, dest int32) bool {
+	for _, v := range src {
+		if v == dest {
+			return true
+		}
+	}
+	return false
+}
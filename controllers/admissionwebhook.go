@@ -0,0 +1,370 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	admissionWebhookCertPath = "/usr/local/certificates/"
+
+	// matchConditionsMinK8sVersion is the first version of k8s enabling the match conditions of admission webhooks
+	// by default.
+	matchConditionsMinK8sVersion = "1.28"
+
+	// Labels of the cluster-scoped and cross-namespace objects of an Ingress Controller, which cannot have an owner
+	// reference.
+	instanceNameLabel      = "networking.kubegems.io/instance-name"
+	instanceNamespaceLabel = "networking.kubegems.io/instance-namespace"
+)
+
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+func admissionWebhookEnabled(instance *v1beta1.NginxIngressController) bool {
+	return instance.Spec.AdmissionWebhook != nil && instance.Spec.AdmissionWebhook.Enabled
+}
+
+// admissionWebhookPort returns the port of the webhook server in the Ingress Controller pods.
+func admissionWebhookPort(instance *v1beta1.NginxIngressController) int32 {
+	if instance.Spec.AdmissionWebhook != nil && instance.Spec.AdmissionWebhook.Port != nil {
+		return *instance.Spec.AdmissionWebhook.Port
+	}
+	return v1beta1.DefaultAdmissionWebhookPort
+}
+
+// admissionWebhookName returns the name of the webhook Service, certificate and Secret.
+func admissionWebhookName(instance *v1beta1.NginxIngressController) string {
+	return instance.Name + "-admission"
+}
+
+// validatingWebhookConfigurationName returns the name of the cluster-scoped ValidatingWebhookConfiguration.
+func validatingWebhookConfigurationName(instance *v1beta1.NginxIngressController) string {
+	return fmt.Sprintf("%s-%s-admission", instance.Namespace, instance.Name)
+}
+
+func admissionWebhookDNSNames(instance *v1beta1.NginxIngressController) []string {
+	name := admissionWebhookName(instance)
+	return []string{
+		fmt.Sprintf("%s.%s.svc", name, instance.Namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", name, instance.Namespace),
+	}
+}
+
+func instanceLabels(instance *v1beta1.NginxIngressController) map[string]string {
+	return map[string]string{instanceNameLabel: instance.Name, instanceNamespaceLabel: instance.Namespace}
+}
+
+// requestForInstanceLabels maps a cluster-scoped or cross-namespace object to a request for the NginxIngressController in its labels.
+func requestForInstanceLabels(object client.Object) []reconcile.Request {
+	labels := object.GetLabels()
+	if labels[instanceNameLabel] == "" || labels[instanceNamespaceLabel] == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: labels[instanceNameLabel], Namespace: labels[instanceNamespaceLabel]}}}
+}
+
+// reconcileAdmissionWebhook provisions the Service, the certificate and the ValidatingWebhookConfiguration of the
+// admission webhook of the Ingress Controller when it is enabled and removes them otherwise.
+func (r *NginxIngressControllerReconciler) reconcileAdmissionWebhook(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	key := types.NamespacedName{Name: admissionWebhookName(instance), Namespace: instance.Namespace}
+
+	if !admissionWebhookEnabled(instance) {
+		if err := r.deleteValidatingWebhookConfiguration(ctx, instance); err != nil {
+			return err
+		}
+		if err := r.deleteIfOwned(ctx, log, key, &corev1.Service{}, instance); err != nil {
+			return err
+		}
+		if err := r.deleteIfOwned(ctx, log, key, &corev1.Secret{}, instance); err != nil {
+			return err
+		}
+		if _, err := r.RESTMapper().RESTMapping(certificateGVK.GroupKind(), certificateGVK.Version); err != nil {
+			if meta.IsNoMatchError(err) {
+				return nil
+			}
+			return err
+		}
+		cert := &unstructured.Unstructured{}
+		cert.SetGroupVersionKind(certificateGVK)
+		return r.deleteIfOwned(ctx, log, key, cert, instance)
+	}
+
+	svc, err := admissionWebhookServiceForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return err
+	}
+	if err := r.apply(ctx, svc); err != nil {
+		log.Error(err, "Failed to apply admission webhook Service")
+		return err
+	}
+
+	var caBundle []byte
+	if instance.Spec.AdmissionWebhook.CertManager != nil {
+		if _, err := r.RESTMapper().RESTMapping(certificateGVK.GroupKind(), certificateGVK.Version); err != nil {
+			if meta.IsNoMatchError(err) {
+				return fmt.Errorf("cert-manager is not installed, the admission webhook certificate cannot be issued")
+			}
+			return err
+		}
+		cert, err := admissionWebhookCertificateForNginxIngressController(instance, r.Scheme)
+		if err != nil {
+			return err
+		}
+		if err := r.apply(ctx, cert); err != nil {
+			log.Error(err, "Failed to apply admission webhook Certificate")
+			return err
+		}
+	} else {
+		current := &corev1.Secret{}
+		if err := r.Get(ctx, key, current); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		secret, err := selfSignedSecretForNginxIngressController(instance, admissionWebhookName(instance), admissionWebhookDNSNames(instance), current, r.Scheme)
+		if err != nil {
+			return err
+		}
+		if err := r.apply(ctx, secret); err != nil {
+			log.Error(err, "Failed to apply admission webhook Secret")
+			return err
+		}
+		caBundle = secret.Data[caCertKey]
+	}
+
+	vwc, err := scopedValidatingWebhookConfiguration(instance, validatingWebhookConfigurationForNginxIngressController(instance, caBundle))
+	if err != nil {
+		return err
+	}
+	if err := r.apply(ctx, vwc); err != nil {
+		log.Error(err, "Failed to apply ValidatingWebhookConfiguration")
+		return err
+	}
+	return nil
+}
+
+// scopedValidatingWebhookConfiguration adds a match condition on the ingress class of the Ingress Controller to the
+// ValidatingWebhookConfiguration when k8s supports it. The typed API of this version of k8s has no match conditions,
+// so the configuration is then returned as an unstructured object.
+func scopedValidatingWebhookConfiguration(instance *v1beta1.NginxIngressController, vwc *admissionregistrationv1.ValidatingWebhookConfiguration) (client.Object, error) {
+	if !runningK8sVersionAtLeast(matchConditionsMinK8sVersion) {
+		return vwc, nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(vwc)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetGroupVersionKind(admissionregistrationv1.SchemeGroupVersion.WithKind("ValidatingWebhookConfiguration"))
+	webhooks, _, err := unstructured.NestedSlice(obj.Object, "webhooks")
+	if err != nil {
+		return nil, err
+	}
+	for _, webhook := range webhooks {
+		webhook.(map[string]interface{})["matchConditions"] = []interface{}{
+			map[string]interface{}{"name": "ingress-class", "expression": ingressClassMatchExpression(instance)},
+		}
+	}
+	if err := unstructured.SetNestedSlice(obj.Object, webhooks, "webhooks"); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// ingressClassMatchExpression returns the CEL expression matching the Ingress resources of the ingress class of the
+// Ingress Controller: by ingressClassName, by the legacy annotation, or without class when the class is the default.
+func ingressClassMatchExpression(instance *v1beta1.NginxIngressController) string {
+	class := strings.ReplaceAll(instance.Spec.IngressClass, "'", "\\'")
+	return fmt.Sprintf("has(object.spec.ingressClassName) ? object.spec.ingressClassName == '%[1]s' : "+
+		"has(object.metadata.annotations) && 'kubernetes.io/ingress.class' in object.metadata.annotations ? "+
+		"object.metadata.annotations['kubernetes.io/ingress.class'] == '%[1]s' : %[2]t",
+		class, instance.IsDefaultIngressClass())
+}
+
+func (r *NginxIngressControllerReconciler) deleteValidatingWebhookConfiguration(ctx context.Context, instance *v1beta1.NginxIngressController) error {
+	vwc := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: validatingWebhookConfigurationName(instance)},
+	}
+	return client.IgnoreNotFound(r.Delete(ctx, vwc))
+}
+
+func admissionWebhookServiceForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*corev1.Service, error) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      admissionWebhookName(instance),
+			Namespace: instance.Namespace,
+			Labels:    map[string]string{"app": instance.Name, "app.kubernetes.io/component": "admission-webhook"},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": instance.Name},
+			Type:     corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "https-webhook",
+					Port:       443,
+					Protocol:   corev1.ProtocolTCP,
+					TargetPort: intstr.FromString("webhook"),
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(instance, svc, scheme); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+// selfSignedSecretForNginxIngressController returns a Secret with a self-signed certificate for the DNS names,
+// reusing the certificate of the current Secret while it is valid.
+func selfSignedSecretForNginxIngressController(instance *v1beta1.NginxIngressController, name string, dnsNames []string, current *corev1.Secret, scheme *runtime.Scheme) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: instance.Namespace,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: current.Data,
+	}
+	if current.Type != "" {
+		secret.Type = current.Type
+	}
+	if needsNewCertificate(current, dnsNames) {
+		caPEM, certPEM, keyPEM, err := generateSelfSignedCertificate(dnsNames[0], dnsNames)
+		if err != nil {
+			return nil, err
+		}
+		secret.Data = map[string][]byte{
+			caCertKey:               caPEM,
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		}
+	}
+	if err := ctrl.SetControllerReference(instance, secret, scheme); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func admissionWebhookCertificateForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*unstructured.Unstructured, error) {
+	certManager := instance.Spec.AdmissionWebhook.CertManager
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateGVK)
+	cert.SetName(admissionWebhookName(instance))
+	cert.SetNamespace(instance.Namespace)
+
+	kind := certManager.IssuerKind
+	if kind == "" {
+		kind = "Issuer"
+	}
+	var dnsNames []interface{}
+	for _, name := range admissionWebhookDNSNames(instance) {
+		dnsNames = append(dnsNames, name)
+	}
+	spec := map[string]interface{}{
+		"secretName": admissionWebhookName(instance),
+		"dnsNames":   dnsNames,
+		"issuerRef": map[string]interface{}{
+			"name":  certManager.IssuerName,
+			"kind":  kind,
+			"group": certificateGVK.Group,
+		},
+	}
+	if err := unstructured.SetNestedMap(cert.Object, spec, "spec"); err != nil {
+		return nil, err
+	}
+	if err := ctrl.SetControllerReference(instance, cert, scheme); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// validatingWebhookConfigurationForNginxIngressController returns the ValidatingWebhookConfiguration of the Ingress Controller.
+// The CA bundle is left to the cainjector of cert-manager when the certificate is issued by cert-manager.
+func validatingWebhookConfigurationForNginxIngressController(instance *v1beta1.NginxIngressController, caBundle []byte) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	webhook := instance.Spec.AdmissionWebhook
+	vwc := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   validatingWebhookConfigurationName(instance),
+			Labels: instanceLabels(instance),
+		},
+	}
+	if webhook.CertManager != nil {
+		vwc.Annotations = map[string]string{
+			"cert-manager.io/inject-ca-from": fmt.Sprintf("%s/%s", instance.Namespace, admissionWebhookName(instance)),
+		}
+	}
+
+	// Without match conditions the webhook also receives the Ingress resources of the other classes, which an
+	// unavailable Ingress Controller must not block.
+	failurePolicy := admissionregistrationv1.Ignore
+	if runningK8sVersionAtLeast(matchConditionsMinK8sVersion) {
+		failurePolicy = admissionregistrationv1.Fail
+	}
+	if webhook.FailurePolicy != nil {
+		failurePolicy = *webhook.FailurePolicy
+	}
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	path := "/networking/v1/ingresses"
+	port := int32(443)
+	namespaceSelector := watchNamespaceSelector(instance)
+	vwc.Webhooks = []admissionregistrationv1.ValidatingWebhook{
+		{
+			Name: "validate.nginx.ingress.kubernetes.io",
+			Rules: []admissionregistrationv1.RuleWithOperations{
+				{
+					Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   []string{"networking.k8s.io"},
+						APIVersions: []string{"v1"},
+						Resources:   []string{"ingresses"},
+					},
+				},
+			},
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Namespace: instance.Namespace,
+					Name:      admissionWebhookName(instance),
+					Path:      &path,
+					Port:      &port,
+				},
+				CABundle: caBundle,
+			},
+			FailurePolicy:           &failurePolicy,
+			NamespaceSelector:       namespaceSelector,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1"},
+		},
+	}
+	return vwc
+}
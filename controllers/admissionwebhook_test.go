@@ -0,0 +1,70 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+func TestScopedValidatingWebhookConfiguration(t *testing.T) {
+	defer func(v *version.Version) { RunningK8sVersion = v }(RunningK8sVersion)
+	instance := newTestInstance()
+	instance.Spec.AdmissionWebhook = &v1beta1.AdmissionWebhook{Enabled: true}
+
+	RunningK8sVersion = version.MustParseGeneric("v1.27.4")
+	vwc := validatingWebhookConfigurationForNginxIngressController(instance, nil)
+	if policy := *vwc.Webhooks[0].FailurePolicy; policy != admissionregistrationv1.Ignore {
+		t.Errorf("validatingWebhookConfigurationForNginxIngressController() set failure policy %s without match conditions", policy)
+	}
+	obj, err := scopedValidatingWebhookConfiguration(instance, vwc)
+	if err != nil {
+		t.Fatalf("scopedValidatingWebhookConfiguration() returned error %v", err)
+	}
+	if obj != vwc {
+		t.Error("scopedValidatingWebhookConfiguration() added match conditions on k8s 1.27")
+	}
+
+	RunningK8sVersion = version.MustParseGeneric("v1.28.0")
+	vwc = validatingWebhookConfigurationForNginxIngressController(instance, nil)
+	if policy := *vwc.Webhooks[0].FailurePolicy; policy != admissionregistrationv1.Fail {
+		t.Errorf("validatingWebhookConfigurationForNginxIngressController() set failure policy %s with match conditions", policy)
+	}
+	obj, err = scopedValidatingWebhookConfiguration(instance, vwc)
+	if err != nil {
+		t.Fatalf("scopedValidatingWebhookConfiguration() returned error %v", err)
+	}
+	webhooks, _, _ := unstructured.NestedSlice(obj.(*unstructured.Unstructured).Object, "webhooks")
+	conditions, _, _ := unstructured.NestedSlice(webhooks[0].(map[string]interface{}), "matchConditions")
+	if len(conditions) != 1 {
+		t.Fatalf("scopedValidatingWebhookConfiguration() set match conditions %v", conditions)
+	}
+	expression := conditions[0].(map[string]interface{})["expression"].(string)
+	if !strings.Contains(expression, "object.spec.ingressClassName == 'nginx'") || !strings.HasSuffix(expression, ": false") {
+		t.Errorf("scopedValidatingWebhookConfiguration() set expression %q", expression)
+	}
+
+	instance.Spec.IngressClassSpec = &v1beta1.IngressClassSpec{Default: true}
+	if expression := ingressClassMatchExpression(instance); !strings.HasSuffix(expression, ": true") {
+		t.Errorf("ingressClassMatchExpression() returned %q for the default ingress class", expression)
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// fieldManager is the field manager of the server-side apply requests of the Operator.
+const fieldManager = "ingress-nginx-operator"
+
+// legacyFieldManager is the field manager of the updates of the Operator before it used server-side apply. The API
+// server names it after the user agent of the client.
+var legacyFieldManager = strings.Split(rest.DefaultKubernetesUserAgent(), "/")[0]
+
+// apply creates or updates the object with server-side apply. The object must be the full desired state of the
+// fields owned by the Operator: the fields it no longer sets are removed, the fields owned by others are left alone.
+func (r *NginxIngressControllerReconciler) apply(ctx context.Context, object client.Object) error {
+	gvk, err := apiutil.GVKForObject(object, r.Scheme)
+	if err != nil {
+		return err
+	}
+	if err := r.upgradeManagedFields(ctx, object, gvk); err != nil {
+		return err
+	}
+	object.GetObjectKind().SetGroupVersionKind(gvk)
+	object.SetManagedFields(nil)
+	object.SetResourceVersion("")
+	return r.Patch(ctx, object, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+}
+
+// deleteIfOwned deletes the object if it exists and is controlled by the NginxIngressController.
+func (r *NginxIngressControllerReconciler) deleteIfOwned(ctx context.Context, log logr.Logger, key types.NamespacedName, object client.Object, instance *v1beta1.NginxIngressController) error {
+	if err := r.Get(ctx, key, object); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !v1.IsControlledBy(object, instance) {
+		return nil
+	}
+	log.Info("Deleting stale object of NGINX Ingress Controller", "Kind", reflect.TypeOf(object).Elem().Name(), "Namespace", key.Namespace, "Name", key.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, object, client.PropagationPolicy(v1.DeletePropagationBackground)))
+}
+
+// upgradeManagedFields moves the fields of the object owned by the updates of the Operator before server-side apply
+// to its apply field manager, so that the fields it no longer sets are removed by the next apply.
+func (r *NginxIngressControllerReconciler) upgradeManagedFields(ctx context.Context, object client.Object, gvk schema.GroupVersionKind) error {
+	var current client.Object
+	if obj, err := r.Scheme.New(gvk); err == nil {
+		current = obj.(client.Object)
+	} else {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		current = u
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(object), current); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	entries, upgraded, err := upgradedManagedFields(current.GetManagedFields())
+	if err != nil || !upgraded {
+		return err
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": current.GetResourceVersion()},
+		{"op": "replace", "path": "/metadata/managedFields", "value": entries},
+	})
+	if err != nil {
+		return err
+	}
+	return r.Patch(ctx, current, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// upgradedManagedFields returns the managed fields with the fields of the legacy updates merged into the apply
+// entry of the Operator, and whether there was any legacy update.
+func upgradedManagedFields(entries []v1.ManagedFieldsEntry) ([]v1.ManagedFieldsEntry, bool, error) {
+	var result []v1.ManagedFieldsEntry
+	var legacy []v1.ManagedFieldsEntry
+	applied := -1
+	for _, entry := range entries {
+		switch {
+		case entry.Manager == legacyFieldManager && entry.Operation == v1.ManagedFieldsOperationUpdate && entry.Subresource == "":
+			legacy = append(legacy, entry)
+		case entry.Manager == fieldManager && entry.Operation == v1.ManagedFieldsOperationApply && entry.Subresource == "":
+			applied = len(result)
+			result = append(result, entry)
+		default:
+			result = append(result, entry)
+		}
+	}
+	if len(legacy) == 0 {
+		return entries, false, nil
+	}
+
+	if applied < 0 {
+		applied = len(result)
+		result = append(result, v1.ManagedFieldsEntry{
+			Manager:    fieldManager,
+			Operation:  v1.ManagedFieldsOperationApply,
+			APIVersion: legacy[0].APIVersion,
+			Time:       legacy[0].Time,
+			FieldsType: "FieldsV1",
+		})
+	}
+	fields, err := managedFieldSet(result[applied])
+	if err != nil {
+		return nil, false, err
+	}
+	for _, entry := range legacy {
+		set, err := managedFieldSet(entry)
+		if err != nil {
+			return nil, false, err
+		}
+		fields = fields.Union(set)
+	}
+	raw, err := fields.ToJSON()
+	if err != nil {
+		return nil, false, err
+	}
+	result[applied].FieldsV1 = &v1.FieldsV1{Raw: raw}
+	return result, true, nil
+}
+
+// managesField returns true if the apply entry of the field manager owns the field at the path.
+func managesField(entries []v1.ManagedFieldsEntry, manager string, path ...interface{}) bool {
+	for _, entry := range entries {
+		if entry.Manager != manager || entry.Operation != v1.ManagedFieldsOperationApply || entry.Subresource != "" {
+			continue
+		}
+		set, err := managedFieldSet(entry)
+		if err != nil {
+			return false
+		}
+		return set.Has(fieldpath.MakePathOrDie(path...))
+	}
+	return false
+}
+
+func managedFieldSet(entry v1.ManagedFieldsEntry) (*fieldpath.Set, error) {
+	set := &fieldpath.Set{}
+	if entry.FieldsV1 == nil {
+		return set, nil
+	}
+	if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
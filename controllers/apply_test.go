@@ -0,0 +1,73 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpgradedManagedFields(t *testing.T) {
+	entries := []metav1.ManagedFieldsEntry{
+		{
+			Manager:    legacyFieldManager,
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: "apps/v1",
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{},"f:paused":{}}}`)},
+		},
+		{
+			Manager:     legacyFieldManager,
+			Operation:   metav1.ManagedFieldsOperationUpdate,
+			APIVersion:  "apps/v1",
+			FieldsType:  "FieldsV1",
+			FieldsV1:    &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:replicas":{}}}`)},
+			Subresource: "status",
+		},
+		{
+			Manager:    "kube-controller-manager",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: "apps/v1",
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{}}}`)},
+		},
+	}
+
+	result, upgraded, err := upgradedManagedFields(entries)
+	if err != nil || !upgraded {
+		t.Fatalf("upgradedManagedFields() returned %t, %v", upgraded, err)
+	}
+	if len(result) != 3 {
+		t.Fatalf("upgradedManagedFields() returned %d entries but expected 3: %v", len(result), result)
+	}
+	for _, entry := range result {
+		if entry.Manager == legacyFieldManager && entry.Subresource == "" {
+			t.Errorf("upgradedManagedFields() kept the legacy entry %v", entry)
+		}
+	}
+	if !managesField(result, fieldManager, "spec", "paused") || !managesField(result, fieldManager, "spec", "replicas") {
+		t.Errorf("upgradedManagedFields() did not move the legacy fields to %s: %v", fieldManager, result)
+	}
+	if managesField(result, fieldManager, "metadata", "annotations") {
+		t.Errorf("upgradedManagedFields() moved the fields of another manager to %s", fieldManager)
+	}
+
+	if _, upgraded, _ := upgradedManagedFields(result); upgraded {
+		t.Error("upgradedManagedFields() upgraded managed fields without legacy entry")
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	defaultTargetCPUUtilizationPercentage = 50
+	// autoscalingV2MinK8sVersion is the first version of k8s serving autoscaling/v2.
+	autoscalingV2MinK8sVersion = "1.23"
+)
+
+// autoscalingEnabled returns whether the replicas of the Ingress Controller are managed by a HorizontalPodAutoscaler.
+func autoscalingEnabled(instance *v1beta1.NginxIngressController) bool {
+	return instance.Spec.Autoscaling != nil && instance.Spec.Autoscaling.Enable &&
+		(instance.Spec.Workload == nil || instance.Spec.Workload.Kind != v1beta1.WorkloadKindDaemonSet)
+}
+
+// reconcileAutoscaling applies the HorizontalPodAutoscaler when autoscaling is enabled and removes it otherwise.
+func (r *NginxIngressControllerReconciler) reconcileAutoscaling(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	if !runningK8sVersionAtLeast(autoscalingV2MinK8sVersion) {
+		if autoscalingEnabled(instance) {
+			return fmt.Errorf("autoscaling requires Kubernetes %s or later, running %s", autoscalingV2MinK8sVersion, RunningK8sVersion)
+		}
+		return nil
+	}
+	if !autoscalingEnabled(instance) {
+		key := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+		return r.deleteIfOwned(ctx, log, key, &autoscalingv2.HorizontalPodAutoscaler{}, instance)
+	}
+
+	hpa, err := horizontalPodAutoscalerForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return err
+	}
+	if err := r.apply(ctx, hpa); err != nil {
+		log.Error(err, "Failed to apply HorizontalPodAutoscaler")
+		return err
+	}
+	return nil
+}
+
+func horizontalPodAutoscalerForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	autoscaling := instance.Spec.Autoscaling
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name,
+			Namespace: instance.Namespace,
+			Labels:    instance.Spec.Workload.ExtraLabels,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       instance.Name,
+			},
+			MinReplicas: autoscaling.MinReplicas,
+			MaxReplicas: autoscaling.MaxReplicas,
+			Metrics:     autoscalingMetrics(autoscaling),
+			Behavior:    autoscaling.Behavior,
+		},
+	}
+	if err := ctrl.SetControllerReference(instance, hpa, scheme); err != nil {
+		return nil, err
+	}
+	return hpa, nil
+}
+
+// autoscalingMetrics returns the metrics of the HorizontalPodAutoscaler, targeting 50% of CPU when none is set.
+func autoscalingMetrics(autoscaling *v1beta1.Autoscaling) []autoscalingv2.MetricSpec {
+	var metrics []autoscalingv2.MetricSpec
+	cpu := autoscaling.TargetCPUUtilizationPercentage
+	if cpu == nil && autoscaling.TargetMemoryUtilizationPercentage == nil && len(autoscaling.Metrics) == 0 {
+		defaultCPU := int32(defaultTargetCPUUtilizationPercentage)
+		cpu = &defaultCPU
+	}
+	if cpu != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceCPU, *cpu))
+	}
+	if memory := autoscaling.TargetMemoryUtilizationPercentage; memory != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceMemory, *memory))
+	}
+	return append(metrics, autoscaling.Metrics...)
+}
+
+func resourceMetric(name corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &utilization,
+			},
+		},
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	defaultCanaryReplicaPercentage = 10
+
+	// canaryTrackLabel tells the canary pods apart from the other Ingress Controller pods. The canary pods have their
+	// own app label, so that the selectors of the Deployment, which the HorizontalPodAutoscaler scales on, of the
+	// PodDisruptionBudget and of the metrics Service leave them out. They share the instance label with the other
+	// pods, which the Service selects to send them their share of the traffic.
+	canaryTrackLabel = "networking.kubegems.io/track"
+)
+
+func canaryName(instance *v1beta1.NginxIngressController) string {
+	return instance.Name + "-canary"
+}
+
+// canaryImage returns the candidate image of the canary.
+func canaryImage(instance *v1beta1.NginxIngressController) string {
+	repository := instance.Spec.Canary.Image.Repository
+	if repository == "" {
+		repository = instance.Spec.Image.Repository
+	}
+	return generateImage(repository, instance.Spec.Canary.Image.Tag)
+}
+
+// canaryEnabled returns true if a canary is set and its image is not promoted yet.
+func canaryEnabled(instance *v1beta1.NginxIngressController) bool {
+	if instance.Spec.Canary == nil || instance.Spec.Workload.Kind != v1beta1.WorkloadKindDeployment {
+		return false
+	}
+	return canaryImage(instance) != specImage(instance)
+}
+
+// canaryReplicas returns the number of canary replicas for the replicas of the Ingress Controller.
+func canaryReplicas(instance *v1beta1.NginxIngressController, replicas int32) int32 {
+	percentage := int32(defaultCanaryReplicaPercentage)
+	if instance.Spec.Canary.ReplicaPercentage != nil {
+		percentage = *instance.Spec.Canary.ReplicaPercentage
+	}
+	canary := (replicas*percentage + 99) / 100
+	if canary < 1 {
+		return 1
+	}
+	return canary
+}
+
+// reconcileCanary runs the canary Deployment while a canary is set and removes it once aborted or promoted.
+func (r *NginxIngressControllerReconciler) reconcileCanary(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	key := types.NamespacedName{Name: canaryName(instance), Namespace: instance.Namespace}
+	if !canaryEnabled(instance) {
+		return r.deleteIfOwned(ctx, log, key, &appsv1.Deployment{}, instance)
+	}
+	// A candidate image that does not support the cluster is not rolled out, the current canary is kept.
+	if err := checkTagVersion(instance, instance.Spec.Canary.Image.Tag); err != nil {
+		log.Info("Not rolling out the canary", "reason", err.Error())
+		return nil
+	}
+
+	// The replicas of the Deployment are set by the HorizontalPodAutoscaler when autoscaling is enabled.
+	replicas := *instance.Spec.Replicas
+	if autoscalingEnabled(instance) {
+		dep := &appsv1.Deployment{}
+		err := r.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, dep)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if err == nil && dep.Spec.Replicas != nil {
+			replicas = *dep.Spec.Replicas
+		}
+	}
+
+	dep, err := canaryDeploymentForNginxIngressController(instance, canaryReplicas(instance, replicas), r.Scheme)
+	if err != nil {
+		return err
+	}
+
+	// The selector of a Deployment is immutable: replace the canary created with the app label of the Ingress
+	// Controller by earlier versions of the Operator. The deletion triggers a new reconciliation.
+	current := &appsv1.Deployment{}
+	if err := r.Get(ctx, key, current); err != nil && !errors.IsNotFound(err) {
+		return err
+	} else if err == nil && !reflect.DeepEqual(current.Spec.Selector, dep.Spec.Selector) {
+		return r.deleteIfOwned(ctx, log, key, current, instance)
+	}
+	if err := r.apply(ctx, dep); err != nil {
+		log.Error(err, "Failed to apply canary Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
+		return err
+	}
+	return nil
+}
+
+func canaryDeploymentForNginxIngressController(instance *v1beta1.NginxIngressController, replicas int32, scheme *runtime.Scheme) (*appsv1.Deployment, error) {
+	template := podTemplateForNginxIngressController(instance)
+	template.Labels["app"] = canaryName(instance)
+	template.Labels[canaryTrackLabel] = "canary"
+	template.Spec.Containers[0].Image = canaryImage(instance)
+	if pullPolicy := instance.Spec.Canary.Image.PullPolicy; pullPolicy != "" {
+		template.Spec.Containers[0].ImagePullPolicy = pullPolicy
+	}
+	// The nginx configuration copied to the writable volume must come from the candidate image.
+	for i := range template.Spec.InitContainers {
+		if template.Spec.InitContainers[i].Name == nginxConfigInitContainer {
+			template.Spec.InitContainers[i].Image = template.Spec.Containers[0].Image
+			template.Spec.InitContainers[i].ImagePullPolicy = template.Spec.Containers[0].ImagePullPolicy
+		}
+	}
+
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      canaryName(instance),
+			Namespace: instance.Namespace,
+			Labels:    instance.Spec.Workload.ExtraLabels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &v1.LabelSelector{
+				MatchLabels: map[string]string{"app": canaryName(instance), canaryTrackLabel: "canary"},
+			},
+			Replicas: &replicas,
+			Template: template,
+		},
+	}
+	if err := ctrl.SetControllerReference(instance, dep, scheme); err != nil {
+		return nil, err
+	}
+	return dep, nil
+}
+
+// canaryStatus returns the state of the canary Deployment, or nil if there is no canary.
+func (r *NginxIngressControllerReconciler) canaryStatus(ctx context.Context, instance *v1beta1.NginxIngressController) (*v1beta1.CanaryStatus, error) {
+	if !canaryEnabled(instance) {
+		return nil, nil
+	}
+	status := &v1beta1.CanaryStatus{Image: canaryImage(instance)}
+
+	dep := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: canaryName(instance), Namespace: instance.Namespace}, dep); err != nil {
+		if errors.IsNotFound(err) {
+			status.Message = "The canary Deployment is not created yet"
+			return status, nil
+		}
+		return nil, err
+	}
+
+	workload := workloadStatusFromDeployment(dep)
+	status.Replicas = workload.replicas
+	status.ReadyReplicas = workload.readyReplicas
+	status.Healthy = workload.upToDate && workload.image == status.Image &&
+		workload.updatedReplicas >= workload.replicas && workload.readyReplicas >= workload.replicas
+	status.Message = fmt.Sprintf("%d/%d canary pods are ready", workload.readyReplicas, workload.replicas)
+	if workload.progressDeadlineExceeded {
+		status.Message = "The canary pods did not become ready in time"
+	}
+	return status, nil
+}
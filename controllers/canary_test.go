@@ -0,0 +1,128 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+func TestCanaryDeploymentForNginxIngressController(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	instance := newTestInstance()
+	instance.Spec.Canary = &v1beta1.Canary{Image: v1beta1.Image{Tag: "v1.3.1"}}
+	if !canaryEnabled(instance) {
+		t.Fatalf("canaryEnabled() returned false for the candidate image %s", canaryImage(instance))
+	}
+
+	for replicas, expected := range map[int32]int32{1: 1, 10: 1, 11: 2, 30: 3} {
+		if result := canaryReplicas(instance, replicas); result != expected {
+			t.Errorf("canaryReplicas(%d) returned %d but expected %d", replicas, result, expected)
+		}
+	}
+
+	dep, err := canaryDeploymentForNginxIngressController(instance, 1, scheme)
+	if err != nil {
+		t.Fatalf("canaryDeploymentForNginxIngressController() returned error %v", err)
+	}
+	labels := dep.Spec.Template.Labels
+	if labels["app"] != canaryName(instance) || labels[instanceNameLabel] != instance.Name || labels[canaryTrackLabel] != "canary" {
+		t.Errorf("canaryDeploymentForNginxIngressController() set pod labels %v but expected the app, instance and track labels", labels)
+	}
+	if image := dep.Spec.Template.Spec.Containers[0].Image; image != "registry.k8s.io/ingress-nginx/controller:v1.3.1" {
+		t.Errorf("canaryDeploymentForNginxIngressController() set image %s but expected the candidate image", image)
+	}
+
+	// Promoting the candidate image removes the canary.
+	instance.Spec.Image.Tag = "v1.3.1"
+	if canaryEnabled(instance) {
+		t.Errorf("canaryEnabled() returned true after the candidate image was promoted")
+	}
+}
+
+func TestCanaryPodsSelectors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	instance := newTestInstance()
+	instance.Spec.Canary = &v1beta1.Canary{Image: v1beta1.Image{Tag: "v1.3.1"}}
+	instance.Spec.Autoscaling = &v1beta1.Autoscaling{Enable: true, MaxReplicas: 3}
+	instance.Spec.PodDisruptionBudget = &v1beta1.PodDisruptionBudget{}
+
+	canary, err := canaryDeploymentForNginxIngressController(instance, 1, scheme)
+	if err != nil {
+		t.Fatalf("canaryDeploymentForNginxIngressController() returned error %v", err)
+	}
+	canaryPod := labels.Set(canary.Spec.Template.Labels)
+	dep, err := deploymentForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatalf("deploymentForNginxIngressController() returned error %v", err)
+	}
+	pod := labels.Set(dep.Spec.Template.Labels)
+
+	// The HorizontalPodAutoscaler counts the pods selected by the Deployment it scales.
+	hpa, err := horizontalPodAutoscalerForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatalf("horizontalPodAutoscalerForNginxIngressController() returned error %v", err)
+	}
+	if hpa.Spec.ScaleTargetRef.Name != dep.Name {
+		t.Fatalf("horizontalPodAutoscalerForNginxIngressController() scales %s but expected %s", hpa.Spec.ScaleTargetRef.Name, dep.Name)
+	}
+	scaleSelector, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scaleSelector.Matches(canaryPod) || !scaleSelector.Matches(pod) {
+		t.Errorf("the HorizontalPodAutoscaler selects the pods with %v, which must leave out the canary pods", scaleSelector)
+	}
+
+	pdb, err := podDisruptionBudgetForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatalf("podDisruptionBudgetForNginxIngressController() returned error %v", err)
+	}
+	pdbSelector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pdbSelector.Matches(canaryPod) || !pdbSelector.Matches(pod) {
+		t.Errorf("the PodDisruptionBudget selects the pods with %v, which must leave out the canary pods", pdbSelector)
+	}
+
+	metrics, err := metricsServiceForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatalf("metricsServiceForNginxIngressController() returned error %v", err)
+	}
+	if selector := labels.SelectorFromSet(metrics.Spec.Selector); selector.Matches(canaryPod) || !selector.Matches(pod) {
+		t.Errorf("the metrics Service selects the pods with %v, which must leave out the canary pods", selector)
+	}
+
+	svc, err := serviceForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatalf("serviceForNginxIngressController() returned error %v", err)
+	}
+	if selector := labels.SelectorFromSet(svc.Spec.Selector); !selector.Matches(canaryPod) || !selector.Matches(pod) {
+		t.Errorf("the Service selects the pods with %v, which must include the canary pods", selector)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/x509"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGenerateSelfSignedCertificate(t *testing.T) {
+	dnsNames := []string{"nginx-admission.default.svc", "nginx-admission.default.svc.cluster.local"}
+	caPEM, certPEM, keyPEM, err := generateSelfSignedCertificate(dnsNames[0], dnsNames)
+	if err != nil {
+		t.Fatalf("generateSelfSignedCertificate() returned error %v", err)
+	}
+
+	ca, err := parseCertificate(caPEM)
+	if err != nil {
+		t.Fatalf("parseCertificate() of the CA returned error %v", err)
+	}
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		t.Fatalf("parseCertificate() of the certificate returned error %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: dnsNames[1], Roots: roots}); err != nil {
+		t.Errorf("certificate is not valid for %v: %v", dnsNames[1], err)
+	}
+
+	secret := &corev1.Secret{Data: map[string][]byte{
+		caCertKey:               caPEM,
+		corev1.TLSCertKey:       certPEM,
+		corev1.TLSPrivateKeyKey: keyPEM,
+	}}
+	if needsNewCertificate(secret, dnsNames) {
+		t.Errorf("needsNewCertificate() returned true for a valid certificate")
+	}
+	if !needsNewCertificate(secret, []string{"other.default.svc"}) {
+		t.Errorf("needsNewCertificate() returned false for a different DNS name")
+	}
+	if !needsNewCertificate(&corev1.Secret{}, dnsNames) {
+		t.Errorf("needsNewCertificate() returned false for an empty Secret")
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// configChecksumAnnotation is the pod template annotation with the checksum of the ConfigMap options that require a
+// restart, so that changing one of them rolls the pods. The other options are hot-reloaded by ingress-nginx.
+const configChecksumAnnotation = "networking.kubegems.io/config-checksum"
+
+func configMapForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name,
+			Namespace: instance.Namespace,
+		},
+		Data: configMapData(instance),
+	}
+	if err := ctrl.SetControllerReference(instance, cm, scheme); err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+// configMapData returns the data of the ConfigMap: the options rendered from the typed config and the custom error
+// pages merged with the free-form configMapData.
+func configMapData(instance *v1beta1.NginxIngressController) map[string]string {
+	data := instance.Spec.Config.ConfigMapData()
+	if codes := customHTTPErrors(instance); codes != "" {
+		data["custom-http-errors"] = codes
+	}
+	if instance.Spec.GeoIP != nil {
+		data["use-geoip2"] = "true"
+	}
+	for k, v := range instance.Spec.ConfigMapData {
+		if _, ok := data[k]; !ok {
+			data[k] = v
+		}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+// restartConfigChecksum returns the checksum of the ConfigMap options that ingress-nginx only reads at startup, or an
+// empty string if none of them is set.
+func restartConfigChecksum(data map[string]string) string {
+	var keys []string
+	for key := range data {
+		if v1beta1.ConfigMapKeyRequiresRestart(key) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", key, data[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
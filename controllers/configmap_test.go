@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+func TestRestartConfigChecksum(t *testing.T) {
+	hotReloaded := map[string]string{"proxy-body-size": "8m", "use-gzip": "true"}
+	if checksum := restartConfigChecksum(hotReloaded); checksum != "" {
+		t.Errorf("restartConfigChecksum(%v) returned %q but expected no checksum", hotReloaded, checksum)
+	}
+
+	before := restartConfigChecksum(map[string]string{"lua-shared-dicts": "configuration_data: 20", "use-gzip": "true"})
+	after := restartConfigChecksum(map[string]string{"lua-shared-dicts": "configuration_data: 20", "use-gzip": "false"})
+	if before == "" || before != after {
+		t.Errorf("restartConfigChecksum() returned %q and %q but expected the same checksum when a hot-reloaded option changes", before, after)
+	}
+	changed := restartConfigChecksum(map[string]string{"lua-shared-dicts": "configuration_data: 40"})
+	if changed == before {
+		t.Errorf("restartConfigChecksum() did not change when an option that requires a restart changed")
+	}
+}
+
+func TestValidateConfigMapData(t *testing.T) {
+	tests := []struct {
+		data    map[string]string
+		wantErr bool
+	}{
+		{data: map[string]string{"use-gzip": "true", "proxy-body-size": "8m", "worker-processes": "auto", "custom-http-errors": "404, 503"}},
+		{data: map[string]string{"jaeger-collector-host": "jaeger.tracing"}},
+		{data: map[string]string{"use-gzip": "True", "enable-brotli": "1", "ssl-redirect": "f"}},
+		{data: map[string]string{"use-gzip": "yes"}, wantErr: true},
+		{data: map[string]string{"keep-alive": "75s"}, wantErr: true},
+		{data: map[string]string{"proxy-body-size": "8 megabytes"}, wantErr: true},
+		{data: map[string]string{"no-such-option": "true"}},
+		{data: map[string]string{"proxy-http-version": "1.0", "proxy-max-temp-file-size": "0", "enable-opentelemetry": "true"}},
+	}
+	for _, test := range tests {
+		instance := newTestInstance()
+		instance.Spec.ConfigMapData = test.data
+		if err := instance.ValidateSpec(); (err != nil) != test.wantErr {
+			t.Errorf("ValidateSpec() with configMapData %v returned %v but expected error %t", test.data, err, test.wantErr)
+		}
+	}
+}
+
+func TestUnknownConfigMapKeys(t *testing.T) {
+	data := map[string]string{"use-gzip": "true", "zz-option": "1", "proxy-http-version": "1.0", "aa-option": "on"}
+	if keys := v1beta1.UnknownConfigMapKeys(data); !reflect.DeepEqual(keys, []string{"aa-option", "zz-option"}) {
+		t.Errorf("UnknownConfigMapKeys() returned %v but expected [aa-option zz-option]", keys)
+	}
+}
+
+func TestConfigMapData(t *testing.T) {
+	timeout := int32(120)
+	forwarded := true
+	instance := newTestInstance()
+	instance.Spec.Config = &v1beta1.Config{
+		ProxyReadTimeout:    &timeout,
+		ProxyBodySize:       "16m",
+		SSLProtocols:        []v1beta1.SSLProtocol{"TLSv1.2", "TLSv1.3"},
+		HSTS:                &v1beta1.HSTS{Enable: true},
+		UseForwardedHeaders: &forwarded,
+		ProxyRealIPCIDR:     []string{"10.0.0.0/8", "192.168.0.0/16"},
+	}
+	instance.Spec.ConfigMapData = map[string]string{"use-gzip": "true"}
+
+	expected := map[string]string{
+		"proxy-read-timeout":    "120",
+		"proxy-body-size":       "16m",
+		"ssl-protocols":         "TLSv1.2 TLSv1.3",
+		"hsts":                  "true",
+		"use-forwarded-headers": "true",
+		"proxy-real-ip-cidr":    "10.0.0.0/8,192.168.0.0/16",
+		"use-gzip":              "true",
+	}
+	if result := configMapData(instance); !reflect.DeepEqual(result, expected) {
+		t.Errorf("configMapData() returned %v but expected %v", result, expected)
+	}
+
+	if err := instance.ValidateSpec(); err != nil {
+		t.Errorf("ValidateSpec() returned %v for a valid typed config", err)
+	}
+
+	instance.Spec.Config.ProxyRealIPCIDR = []string{"10.0.0.0/8", "192.168.0.1", "10.0.0.0/33"}
+	if errs, ok := instance.ValidateSpec().(utilerrors.Aggregate); !ok || len(errs.Errors()) != 2 {
+		t.Errorf("ValidateSpec() returned %v but expected the 2 invalid proxyRealIPCIDR entries to be rejected", errs)
+	}
+	instance.Spec.Config.ProxyRealIPCIDR = nil
+
+	instance.Spec.ConfigMapData["proxy-body-size"] = "1m"
+	if err := instance.ValidateSpec(); err == nil {
+		t.Errorf("ValidateSpec() accepted a configMapData key already set by the typed config")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func daemonSetForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*appsv1.DaemonSet, error) {
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      instance.Name,
+			Namespace: instance.Namespace,
+			Labels:    instance.Spec.Workload.ExtraLabels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &v1.LabelSelector{
+				MatchLabels: map[string]string{"app": instance.Name},
+			},
+			Template: podTemplateForNginxIngressController(instance),
+		},
+	}
+	setDaemonSetStrategy(ds, instance)
+	if err := ctrl.SetControllerReference(instance, ds, scheme); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// reconcileDaemonSet applies the DaemonSet of the Ingress Controller.
+func (r *NginxIngressControllerReconciler) reconcileDaemonSet(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	ds, err := daemonSetForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return err
+	}
+	if err := r.apply(ctx, ds); err != nil {
+		log.Error(err, "Failed to apply DaemonSet", "DaemonSet.Namespace", ds.Namespace, "DaemonSet.Name", ds.Name)
+		return err
+	}
+	return nil
+}
+
+// reconcileWorkload reconciles the workload of the kind set in the spec and removes
+// the workload of the other kind, so that switching the kind migrates the Ingress Controller.
+func (r *NginxIngressControllerReconciler) reconcileWorkload(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	// An image that does not support the cluster is not rolled out, and there is no running image to keep yet.
+	if err := checkTagVersion(instance, imageTag(instance)); err != nil && instance.Status.Image == "" {
+		log.Info("Not creating the workload", "reason", err.Error())
+		return nil
+	}
+
+	var stale client.Object
+	if instance.Spec.Workload.Kind == v1beta1.WorkloadKindDaemonSet {
+		if err := r.reconcileDaemonSet(ctx, log, instance); err != nil {
+			return err
+		}
+		stale = &appsv1.Deployment{}
+	} else {
+		if err := r.reconcileDeployment(ctx, log, instance); err != nil {
+			return err
+		}
+		stale = &appsv1.DaemonSet{}
+	}
+	return r.deleteIfOwned(ctx, log, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, stale, instance)
+}
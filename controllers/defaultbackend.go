@@ -0,0 +1,273 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// defaultBackendPort is the HTTP port of the managed default backend pods.
+	defaultBackendPort = 8080
+	// errorPagesPath is where the managed default backend serves the custom error pages from.
+	errorPagesPath = "/www"
+)
+
+// defaultBackendName returns the name of the Deployment and the Service of the managed default backend.
+func defaultBackendName(instance *v1beta1.NginxIngressController) string {
+	return instance.Name + "-default-backend"
+}
+
+// errorPagesName returns the name of the ConfigMap of the custom error pages.
+func errorPagesName(instance *v1beta1.NginxIngressController) string {
+	return instance.Name + "-error-pages"
+}
+
+// customHTTPErrors returns the sorted status codes of the custom error pages, as the custom-http-errors option.
+func customHTTPErrors(instance *v1beta1.NginxIngressController) string {
+	if instance.Spec.CustomErrorPages == nil {
+		return ""
+	}
+	codes := make([]string, 0, len(instance.Spec.CustomErrorPages.Pages))
+	for code := range instance.Spec.CustomErrorPages.Pages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return strings.Join(codes, ",")
+}
+
+// managedDefaultBackend returns true if the Operator runs the default backend of the Ingress Controller.
+func managedDefaultBackend(instance *v1beta1.NginxIngressController) bool {
+	return instance.Spec.DefaultBackend != nil && instance.Spec.DefaultBackend.Service == nil
+}
+
+// defaultBackendImage returns the image and the pull policy of the managed default backend. The unset fields of the
+// image are defaulted here rather than in the spec, so that the default image follows the custom error pages.
+func defaultBackendImage(instance *v1beta1.NginxIngressController) (string, corev1.PullPolicy) {
+	image := instance.Spec.DefaultBackend.Image
+	repository, tag := v1beta1.DefaultBackendImageRepository, v1beta1.DefaultBackendImageTag
+	if instance.Spec.CustomErrorPages != nil {
+		repository, tag = v1beta1.ErrorPagesImageRepository, v1beta1.ErrorPagesImageTag
+	}
+	if image.Repository != "" {
+		repository = image.Repository
+	}
+	if image.Tag != "" {
+		tag = image.Tag
+	}
+	pullPolicy := image.PullPolicy
+	if pullPolicy == "" {
+		pullPolicy = corev1.PullIfNotPresent
+	}
+	return generateImage(repository, tag), pullPolicy
+}
+
+// defaultBackendService returns the <namespace>/<name> of the default backend Service, or an empty string if
+// ingress-nginx serves the unmatched requests itself.
+func defaultBackendService(instance *v1beta1.NginxIngressController) string {
+	backend := instance.Spec.DefaultBackend
+	switch {
+	case backend == nil:
+		return ""
+	case backend.Service != nil:
+		namespace := backend.Service.Namespace
+		if namespace == "" {
+			namespace = instance.Namespace
+		}
+		return fmt.Sprintf("%s/%s", namespace, backend.Service.Name)
+	default:
+		return fmt.Sprintf("$(POD_NAMESPACE)/%s", defaultBackendName(instance))
+	}
+}
+
+// reconcileDefaultBackend runs the managed default backend and its custom error pages when they are enabled and
+// removes them otherwise.
+func (r *NginxIngressControllerReconciler) reconcileDefaultBackend(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	if instance.Spec.CustomErrorPages == nil {
+		key := types.NamespacedName{Name: errorPagesName(instance), Namespace: instance.Namespace}
+		if err := r.deleteIfOwned(ctx, log, key, &corev1.ConfigMap{}, instance); err != nil {
+			return err
+		}
+	} else {
+		cm, err := errorPagesConfigMapForNginxIngressController(instance, r.Scheme)
+		if err != nil {
+			return err
+		}
+		if err := r.apply(ctx, cm); err != nil {
+			log.Error(err, "Failed to apply error pages ConfigMap")
+			return err
+		}
+	}
+
+	if !managedDefaultBackend(instance) {
+		key := types.NamespacedName{Name: defaultBackendName(instance), Namespace: instance.Namespace}
+		if err := r.deleteIfOwned(ctx, log, key, &appsv1.Deployment{}, instance); err != nil {
+			return err
+		}
+		return r.deleteIfOwned(ctx, log, key, &corev1.Service{}, instance)
+	}
+
+	dep, err := defaultBackendDeploymentForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return err
+	}
+	if err := r.apply(ctx, dep); err != nil {
+		log.Error(err, "Failed to apply default backend Deployment")
+		return err
+	}
+	svc, err := defaultBackendServiceForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return err
+	}
+	if err := r.apply(ctx, svc); err != nil {
+		log.Error(err, "Failed to apply default backend Service")
+		return err
+	}
+	return nil
+}
+
+func defaultBackendDeploymentForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*appsv1.Deployment, error) {
+	backend := instance.Spec.DefaultBackend
+	labels := map[string]string{"app": defaultBackendName(instance)}
+	image, pullPolicy := defaultBackendImage(instance)
+	replicas := int32(1)
+	if backend.Replicas != nil {
+		replicas = *backend.Replicas
+	}
+	runAsUser := int64(65534)
+	runAsNonRoot := true
+	readOnlyRootFilesystem := true
+	allowPrivilegeEscalation := false
+
+	probe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/healthz",
+				Port: intstr.FromInt(defaultBackendPort),
+			},
+		},
+		InitialDelaySeconds: 5,
+	}
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      defaultBackendName(instance),
+			Namespace: instance.Namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &v1.LabelSelector{MatchLabels: labels},
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "default-backend",
+							Image:           image,
+							ImagePullPolicy: pullPolicy,
+							Ports: []corev1.ContainerPort{
+								{Name: "http", ContainerPort: defaultBackendPort, Protocol: corev1.ProtocolTCP},
+							},
+							Resources: backend.Resources,
+							SecurityContext: &corev1.SecurityContext{
+								Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+								RunAsUser:                &runAsUser,
+								RunAsNonRoot:             &runAsNonRoot,
+								ReadOnlyRootFilesystem:   &readOnlyRootFilesystem,
+								AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+							},
+							LivenessProbe:  probe,
+							ReadinessProbe: probe,
+						},
+					},
+				},
+			},
+		},
+	}
+	if instance.Spec.CustomErrorPages != nil {
+		podSpec := &dep.Spec.Template.Spec
+		podSpec.Containers[0].VolumeMounts = []corev1.VolumeMount{
+			{Name: "error-pages", MountPath: errorPagesPath, ReadOnly: true},
+		}
+		podSpec.Volumes = []corev1.Volume{
+			{
+				Name: "error-pages",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: errorPagesName(instance)},
+					},
+				},
+			},
+		}
+	}
+	if err := ctrl.SetControllerReference(instance, dep, scheme); err != nil {
+		return nil, err
+	}
+	return dep, nil
+}
+
+func defaultBackendServiceForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*corev1.Service, error) {
+	svc := &corev1.Service{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      defaultBackendName(instance),
+			Namespace: instance.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": defaultBackendName(instance)},
+			Type:     corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromString("http")},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(instance, svc, scheme); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+// errorPagesConfigMapForNginxIngressController returns the ConfigMap of the custom error pages, with a <code>.html
+// file per status code.
+func errorPagesConfigMapForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*corev1.ConfigMap, error) {
+	data := map[string]string{}
+	for code, page := range instance.Spec.CustomErrorPages.Pages {
+		data[code+".html"] = page
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      errorPagesName(instance),
+			Namespace: instance.Namespace,
+		},
+		Data: data,
+	}
+	if err := ctrl.SetControllerReference(instance, cm, scheme); err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
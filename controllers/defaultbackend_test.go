@@ -0,0 +1,153 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+func TestDefaultBackendAndTLSArgs(t *testing.T) {
+	tests := []struct {
+		backend  *v1beta1.DefaultBackend
+		tls      *v1beta1.DefaultTLS
+		expected []string
+	}{
+		{
+			backend:  &v1beta1.DefaultBackend{Service: &v1beta1.ServiceReference{Namespace: "errors", Name: "pages"}},
+			tls:      &v1beta1.DefaultTLS{SecretName: "wildcard"},
+			expected: []string{"--default-backend-service=errors/pages", "--default-ssl-certificate=$(POD_NAMESPACE)/wildcard"},
+		},
+		{
+			backend:  &v1beta1.DefaultBackend{},
+			tls:      &v1beta1.DefaultTLS{},
+			expected: []string{"--default-backend-service=$(POD_NAMESPACE)/nginx-default-backend", "--default-ssl-certificate=$(POD_NAMESPACE)/nginx-default-tls"},
+		},
+	}
+	for _, test := range tests {
+		instance := newTestInstance()
+		instance.Spec.DefaultBackend = test.backend
+		instance.Spec.DefaultTLS = test.tls
+		args := generatePodArgs(instance)
+		for _, arg := range test.expected {
+			if !containsStr(args, arg) {
+				t.Errorf("generatePodArgs() returned %v but expected %s", args, arg)
+			}
+		}
+	}
+}
+
+func TestDefaultBackendDeploymentForNginxIngressController(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	instance := newTestInstance()
+	instance.Spec.DefaultBackend = &v1beta1.DefaultBackend{}
+	instance.Default()
+	if !managedDefaultBackend(instance) {
+		t.Fatal("managedDefaultBackend() returned false without a Service reference")
+	}
+
+	dep, err := defaultBackendDeploymentForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatalf("defaultBackendDeploymentForNginxIngressController() returned error %v", err)
+	}
+	if image := dep.Spec.Template.Spec.Containers[0].Image; image != "registry.k8s.io/defaultbackend-amd64:1.5" {
+		t.Errorf("defaultBackendDeploymentForNginxIngressController() set image %s but expected the default image", image)
+	}
+	if dep.Spec.Replicas == nil || *dep.Spec.Replicas != 1 {
+		t.Errorf("defaultBackendDeploymentForNginxIngressController() set replicas %v but expected 1", dep.Spec.Replicas)
+	}
+}
+
+func TestCustomErrorPages(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	instance := newTestInstance()
+	instance.Spec.CustomErrorPages = &v1beta1.CustomErrorPages{
+		Pages: map[string]string{"503": "<h1>Maintenance</h1>", "404": "<h1>Not found</h1>"},
+	}
+	instance.Default()
+	if err := instance.ValidateSpec(); err != nil {
+		t.Fatalf("ValidateSpec() returned error %v", err)
+	}
+
+	if codes := configMapData(instance)["custom-http-errors"]; codes != "404,503" {
+		t.Errorf("configMapData() set custom-http-errors %q but expected 404,503", codes)
+	}
+	if !containsStr(generatePodArgs(instance), "--default-backend-service=$(POD_NAMESPACE)/nginx-default-backend") {
+		t.Errorf("generatePodArgs() did not set the managed default backend")
+	}
+
+	cm, err := errorPagesConfigMapForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Data["404.html"] != "<h1>Not found</h1>" || len(cm.OwnerReferences) != 1 {
+		t.Errorf("errorPagesConfigMapForNginxIngressController() returned %v but expected an owned page per status code", cm)
+	}
+
+	dep, err := defaultBackendDeploymentForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	container := dep.Spec.Template.Spec.Containers[0]
+	if container.Image != "registry.k8s.io/ingress-nginx/nginx-errors:v20230505" {
+		t.Errorf("defaultBackendDeploymentForNginxIngressController() set image %s but expected the error pages image", container.Image)
+	}
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].MountPath != errorPagesPath {
+		t.Errorf("defaultBackendDeploymentForNginxIngressController() mounted %v but expected the error pages", container.VolumeMounts)
+	}
+
+	instance.Spec.CustomErrorPages.Pages["200"] = "OK"
+	if err := instance.ValidateSpec(); err == nil {
+		t.Errorf("ValidateSpec() accepted a page for a status code that is not an error")
+	}
+}
+
+func TestDefaultBackendImageFollowsErrorPages(t *testing.T) {
+	instance := newTestInstance()
+	instance.Spec.DefaultBackend = &v1beta1.DefaultBackend{}
+	instance.Default()
+	if image := instance.Spec.DefaultBackend.Image; image != (v1beta1.Image{}) {
+		t.Fatalf("Default() persisted the default backend image %v", image)
+	}
+
+	instance.Spec.CustomErrorPages = &v1beta1.CustomErrorPages{Pages: map[string]string{"503": "<h1>Maintenance</h1>"}}
+	instance.Default()
+	if image, _ := defaultBackendImage(instance); image != "registry.k8s.io/ingress-nginx/nginx-errors:v20230505" {
+		t.Errorf("defaultBackendImage() returned %s but expected the error pages image once pages are added", image)
+	}
+
+	instance.Spec.DefaultBackend.Image.Repository = v1beta1.DefaultBackendImageRepository
+	if err := instance.ValidateSpec(); err == nil {
+		t.Errorf("ValidateSpec() accepted custom error pages served by the default backend image")
+	}
+
+	instance.Spec.CustomErrorPages = nil
+	instance.Spec.DefaultBackend = &v1beta1.DefaultBackend{}
+	instance.Default()
+	instance.Spec.DefaultBackend.Service = &v1beta1.ServiceReference{Name: "pages"}
+	if err := instance.ValidateSpec(); err != nil {
+		t.Errorf("ValidateSpec() returned %v after switching the defaulted backend to a Service", err)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+// defaultTLSSecretName returns the name of the Secret of the default certificate: the referenced one or the one
+// generated by the Operator.
+func defaultTLSSecretName(instance *v1beta1.NginxIngressController) string {
+	if instance.Spec.DefaultTLS != nil && instance.Spec.DefaultTLS.SecretName != "" {
+		return instance.Spec.DefaultTLS.SecretName
+	}
+	return instance.Name + "-default-tls"
+}
+
+// generatedDefaultTLS returns true if the Operator generates the default certificate of the Ingress Controller.
+func generatedDefaultTLS(instance *v1beta1.NginxIngressController) bool {
+	return instance.Spec.DefaultTLS != nil && instance.Spec.DefaultTLS.SecretName == ""
+}
+
+// defaultTLSDNSNames returns the DNS names of the generated default certificate.
+func defaultTLSDNSNames(instance *v1beta1.NginxIngressController) []string {
+	if len(instance.Spec.DefaultTLS.DNSNames) > 0 {
+		return instance.Spec.DefaultTLS.DNSNames
+	}
+	return []string{
+		fmt.Sprintf("%s.%s.svc", instance.Name, instance.Namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", instance.Name, instance.Namespace),
+	}
+}
+
+// reconcileDefaultTLS generates the self-signed default certificate when it is requested and removes it otherwise.
+func (r *NginxIngressControllerReconciler) reconcileDefaultTLS(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	key := types.NamespacedName{Name: instance.Name + "-default-tls", Namespace: instance.Namespace}
+	if !generatedDefaultTLS(instance) {
+		return r.deleteIfOwned(ctx, log, key, &corev1.Secret{}, instance)
+	}
+
+	current := &corev1.Secret{}
+	if err := r.Get(ctx, key, current); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	secret, err := selfSignedSecretForNginxIngressController(instance, key.Name, defaultTLSDNSNames(instance), current, r.Scheme)
+	if err != nil {
+		return err
+	}
+	if err := r.apply(ctx, secret); err != nil {
+		log.Error(err, "Failed to apply default TLS Secret")
+		return err
+	}
+	return nil
+}
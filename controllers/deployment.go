@@ -17,23 +17,26 @@ limitations under the License.
 package controllers
 
 import (
-	"reflect"
+	"context"
 
+	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"kubegems.io/ingress-nginx-operator/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func deploymentForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*appsv1.Deployment, error) {
-	runAsUser := new(int64)
-	allowPrivilegeEscalation := new(bool)
-	*runAsUser = 101
-	*allowPrivilegeEscalation = true
+// replicasHandoverFieldManager owns the replicas of the Deployment while they are handed over to the
+// HorizontalPodAutoscaler.
+const replicasHandoverFieldManager = "ingress-nginx-operator-handover-to-hpa"
 
+func deploymentForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*appsv1.Deployment, error) {
 	dep := &appsv1.Deployment{
 		ObjectMeta: v1.ObjectMeta{
 			Name:      instance.Name,
@@ -45,146 +48,256 @@ func deploymentForNginxIngressController(instance *v1beta1.NginxIngressControlle
 				MatchLabels: map[string]string{"app": instance.Name},
 			},
 			Replicas: instance.Spec.Replicas,
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: v1.ObjectMeta{
-					Name:      instance.Name,
-					Namespace: instance.Namespace,
-					Labels:    mergeLabels(map[string]string{"app": instance.Name}, instance.Spec.Workload.ExtraLabels),
-				},
-				Spec: corev1.PodSpec{
-					ServiceAccountName: instance.Name,
-					Containers: []corev1.Container{
+			Template: podTemplateForNginxIngressController(instance),
+		},
+	}
+	setDeploymentStrategy(dep, instance)
+	// Leave the replicas to the HorizontalPodAutoscaler when autoscaling is enabled, once they are handed over.
+	if autoscalingEnabled(instance) {
+		dep.Spec.Replicas = nil
+	}
+	if err := ctrl.SetControllerReference(instance, dep, scheme); err != nil {
+		return nil, err
+	}
+	return dep, nil
+}
+
+// podTemplateForNginxIngressController returns the pod template shared by the Deployment and the DaemonSet workloads.
+func podTemplateForNginxIngressController(instance *v1beta1.NginxIngressController) corev1.PodTemplateSpec {
+	workload := instance.Spec.Workload
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        instance.Name,
+			Namespace:   instance.Namespace,
+			Labels:      mergeLabels(map[string]string{"app": instance.Name, instanceNameLabel: instance.Name}, workload.ExtraLabels),
+			Annotations: podAnnotations(instance),
+		},
+		Spec: corev1.PodSpec{
+			ServiceAccountName:        instance.Name,
+			NodeSelector:              workload.NodeSelector,
+			Tolerations:               workload.Tolerations,
+			Affinity:                  workload.Affinity,
+			TopologySpreadConstraints: workload.TopologySpreadConstraints,
+			PriorityClassName:         workload.PriorityClassName,
+			HostNetwork:               workload.HostNetwork,
+			DNSPolicy:                 dnsPolicy(workload),
+			SecurityContext:           podSecurityContext(instance),
+			Containers: []corev1.Container{
+				{
+					Name:            instance.Name,
+					Image:           workloadImage(instance),
+					ImagePullPolicy: instance.Spec.Image.PullPolicy,
+					Args:            generatePodArgs(instance),
+					Ports: []corev1.ContainerPort{
 						{
-							Name:            instance.Name,
-							Image:           generateImage(instance.Spec.Image.Repository, instance.Spec.Image.Tag),
-							ImagePullPolicy: instance.Spec.Image.PullPolicy,
-							Args:            generatePodArgs(instance),
-							Ports: []corev1.ContainerPort{
-								{
-									Name:          "http",
-									ContainerPort: 80,
-								},
-								{
-									Name:          "https",
-									ContainerPort: 443,
-								},
-								{
-									Name:          "metrics",
-									ContainerPort: 10254,
-								},
-							},
-							SecurityContext: &corev1.SecurityContext{
-								Capabilities: &corev1.Capabilities{
-									Drop: []corev1.Capability{"ALL"},
-									Add:  []corev1.Capability{"NET_BIND_SERVICE"},
-								},
-								RunAsUser:                runAsUser,
-								AllowPrivilegeEscalation: allowPrivilegeEscalation,
-							},
-							Env: []corev1.EnvVar{
-								{
-									Name: "POD_NAMESPACE",
-									ValueFrom: &corev1.EnvVarSource{
-										FieldRef: &corev1.ObjectFieldSelector{
-											FieldPath: "metadata.namespace",
-										},
-									},
-								},
-								{
-									Name: "POD_NAME",
-									ValueFrom: &corev1.EnvVarSource{
-										FieldRef: &corev1.ObjectFieldSelector{
-											FieldPath: "metadata.name",
-										},
-									},
-								},
-								{
-									Name:  "LD_PRELOAD", // Enable mimalloc as a drop-in replacement for malloc.
-									Value: "/usr/local/lib/libmimalloc.so",
-								},
-							},
-							Resources: instance.Spec.Workload.Resources,
-							Lifecycle: &corev1.Lifecycle{
-								PreStop: &corev1.LifecycleHandler{
-									Exec: &corev1.ExecAction{
-										Command: []string{"/wait-shutdown"},
-									},
+							Name:          "http",
+							ContainerPort: httpPort(instance),
+							Protocol:      corev1.ProtocolTCP,
+						},
+						{
+							Name:          "https",
+							ContainerPort: httpsPort(instance),
+							Protocol:      corev1.ProtocolTCP,
+						},
+						{
+							Name:          "metrics",
+							ContainerPort: metricsPort(instance),
+							Protocol:      corev1.ProtocolTCP,
+						},
+					},
+					SecurityContext: containerSecurityContext(instance),
+					Env: []corev1.EnvVar{
+						{
+							Name: "POD_NAMESPACE",
+							ValueFrom: &corev1.EnvVarSource{
+								FieldRef: &corev1.ObjectFieldSelector{
+									FieldPath: "metadata.namespace",
 								},
 							},
-							LivenessProbe: &corev1.Probe{
-								FailureThreshold: 5,
-								ProbeHandler: corev1.ProbeHandler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path: "/healthz",
-										Port: intstr.FromInt(10254),
-									},
+						},
+						{
+							Name: "POD_NAME",
+							ValueFrom: &corev1.EnvVarSource{
+								FieldRef: &corev1.ObjectFieldSelector{
+									FieldPath: "metadata.name",
 								},
-								InitialDelaySeconds: 10,
 							},
-							ReadinessProbe: &corev1.Probe{
-								FailureThreshold: 3,
-								ProbeHandler: corev1.ProbeHandler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path: "/healthz",
-										Port: intstr.FromInt(10254),
-									},
-								},
-								InitialDelaySeconds: 10,
+						},
+						{
+							Name:  "LD_PRELOAD", // Enable mimalloc as a drop-in replacement for malloc.
+							Value: "/usr/local/lib/libmimalloc.so",
+						},
+					},
+					Resources: instance.Spec.Workload.Resources,
+					Lifecycle: &corev1.Lifecycle{
+						PreStop: &corev1.LifecycleHandler{
+							Exec: &corev1.ExecAction{
+								Command: []string{"/wait-shutdown"},
 							},
 						},
 					},
+					LivenessProbe: probe(instance, workload.LivenessProbe, &corev1.Probe{
+						FailureThreshold:    5,
+						InitialDelaySeconds: 10,
+					}),
+					ReadinessProbe: probe(instance, workload.ReadinessProbe, &corev1.Probe{
+						FailureThreshold:    3,
+						InitialDelaySeconds: 10,
+					}),
+					StartupProbe: probe(instance, workload.StartupProbe, nil),
 				},
 			},
 		},
 	}
-	if err := ctrl.SetControllerReference(instance, dep, scheme); err != nil {
-		return nil, err
+
+	if admissionWebhookEnabled(instance) {
+		container := &template.Spec.Containers[0]
+		container.Ports = append(container.Ports, corev1.ContainerPort{
+			Name:          "webhook",
+			ContainerPort: admissionWebhookPort(instance),
+			Protocol:      corev1.ProtocolTCP,
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "webhook-cert",
+			MountPath: admissionWebhookCertPath,
+			ReadOnly:  true,
+		})
+		template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+			Name: "webhook-cert",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: admissionWebhookName(instance),
+					Items: []corev1.KeyToPath{
+						{Key: corev1.TLSCertKey, Path: "cert"},
+						{Key: corev1.TLSPrivateKeyKey, Path: "key"},
+					},
+				},
+			},
+		})
 	}
-	return dep, nil
+
+	addWritableVolumes(instance, &template)
+	addGeoIP(instance, &template)
+
+	container := &template.Spec.Containers[0]
+	container.Ports = append(container.Ports, proxiedContainerPorts(instance)...)
+	container.Env = append(container.Env, workload.ExtraEnv...)
+	container.VolumeMounts = append(container.VolumeMounts, workload.ExtraVolumeMounts...)
+	template.Spec.Volumes = append(template.Spec.Volumes, workload.ExtraVolumes...)
+	template.Spec.InitContainers = append(template.Spec.InitContainers, workload.InitContainers...)
+	template.Spec.Containers = append(template.Spec.Containers, workload.ExtraContainers...)
+	return template
 }
 
-func hasDeploymentChanged(dep *appsv1.Deployment, instance *v1beta1.NginxIngressController) bool {
-	defaultReplicaCount := int32(1)
-	if dep.Spec.Replicas != nil && instance.Spec.Replicas == nil && *dep.Spec.Replicas != defaultReplicaCount ||
-		dep.Spec.Replicas != nil && instance.Spec.Replicas != nil && *dep.Spec.Replicas != *instance.Spec.Replicas {
-		return true
+// podAnnotations returns the annotations of the pod template: the pod annotations of the workload and the checksum of
+// the ConfigMap options that require a restart.
+func podAnnotations(instance *v1beta1.NginxIngressController) map[string]string {
+	checksum := restartConfigChecksum(configMapData(instance))
+	if checksum == "" {
+		return instance.Spec.Workload.PodAnnotations
 	}
-
-	// There is only 1 container in our template
-	container := dep.Spec.Template.Spec.Containers[0]
-	if container.Image != generateImage(instance.Spec.Image.Repository, instance.Spec.Image.Tag) {
-		return true
+	annotations := map[string]string{}
+	for k, v := range instance.Spec.Workload.PodAnnotations {
+		annotations[k] = v
 	}
+	annotations[configChecksumAnnotation] = checksum
+	return annotations
+}
 
-	if container.ImagePullPolicy != instance.Spec.Image.PullPolicy {
-		return true
+// probe returns the probe of the nginx container: the one of the workload merged with the default one, which may be
+// nil. The unset fields of the workload probe keep their default values, and a probe without handler checks /healthz
+// on the metrics port.
+func probe(instance *v1beta1.NginxIngressController, override, defaults *corev1.Probe) *corev1.Probe {
+	p := defaults.DeepCopy()
+	if override != nil {
+		p = override.DeepCopy()
+		if defaults != nil {
+			mergeProbeDefaults(p, defaults)
+		}
+	}
+	if p == nil {
+		return nil
+	}
+	if p.ProbeHandler == (corev1.ProbeHandler{}) {
+		p.ProbeHandler = corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/healthz",
+				Port: intstr.FromInt(int(metricsPort(instance))),
+			},
+		}
 	}
+	return p
+}
 
-	if instance.Spec.Workload == nil {
-		instance.Spec.Workload = &v1beta1.Workload{}
+// mergeProbeDefaults sets the unset fields of the probe from the default one.
+func mergeProbeDefaults(p, defaults *corev1.Probe) {
+	if p.ProbeHandler == (corev1.ProbeHandler{}) {
+		p.ProbeHandler = *defaults.ProbeHandler.DeepCopy()
+	}
+	for _, setting := range []struct{ value, def *int32 }{
+		{&p.InitialDelaySeconds, &defaults.InitialDelaySeconds},
+		{&p.TimeoutSeconds, &defaults.TimeoutSeconds},
+		{&p.PeriodSeconds, &defaults.PeriodSeconds},
+		{&p.SuccessThreshold, &defaults.SuccessThreshold},
+		{&p.FailureThreshold, &defaults.FailureThreshold},
+	} {
+		if *setting.value == 0 {
+			*setting.value = *setting.def
+		}
 	}
-	if !reflect.DeepEqual(dep.Labels, instance.Spec.Workload.ExtraLabels) {
-		return true
+	if p.TerminationGracePeriodSeconds == nil && defaults.TerminationGracePeriodSeconds != nil {
+		seconds := *defaults.TerminationGracePeriodSeconds
+		p.TerminationGracePeriodSeconds = &seconds
 	}
+}
 
-	if HasDifferentResources(container.Resources, instance.Spec.Workload.Resources) {
-		return true
+// handOverReplicas keeps the current replicas of the Deployment with a separate field manager while the Operator
+// owns them, so that the API server does not reset them to 1 when the Operator stops applying them for the
+// HorizontalPodAutoscaler. The HorizontalPodAutoscaler takes them over on its next scale.
+func (r *NginxIngressControllerReconciler) handOverReplicas(ctx context.Context, dep *appsv1.Deployment) error {
+	current := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: dep.Name, Namespace: dep.Namespace}, current); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if current.Spec.Replicas == nil || !managesField(current.ManagedFields, fieldManager, "spec", "replicas") {
+		return nil
 	}
+	handover := &unstructured.Unstructured{}
+	handover.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	handover.SetName(current.Name)
+	handover.SetNamespace(current.Namespace)
+	if err := unstructured.SetNestedField(handover.Object, int64(*current.Spec.Replicas), "spec", "replicas"); err != nil {
+		return err
+	}
+	return r.Patch(ctx, handover, client.Apply, client.FieldOwner(replicasHandoverFieldManager))
+}
 
-	return hasDifferentArguments(container, instance)
+// dnsPolicy returns the DNS policy of the Workload, defaulting to the one the pods need to resolve cluster services.
+func dnsPolicy(workload *v1beta1.Workload) corev1.DNSPolicy {
+	if workload.DNSPolicy != "" {
+		return workload.DNSPolicy
+	}
+	if workload.HostNetwork {
+		return corev1.DNSClusterFirstWithHostNet
+	}
+	return corev1.DNSClusterFirst
 }
 
-func updateDeployment(dep *appsv1.Deployment, instance *v1beta1.NginxIngressController) *appsv1.Deployment {
-	dep.Spec.Replicas = instance.Spec.Replicas
-	if instance.Spec.Replicas == nil {
-		defaultReplicaCount := new(int32)
-		*defaultReplicaCount = 1
-		dep.Spec.Replicas = defaultReplicaCount
-	}
-	dep.Spec.Template.Spec.Containers[0].Image = generateImage(instance.Spec.Image.Repository, instance.Spec.Image.Tag)
-	dep.Spec.Template.Spec.Containers[0].Args = generatePodArgs(instance)
-	dep.Spec.Template.Spec.Containers[0].Resources = instance.Spec.Workload.Resources
-	dep.Labels = instance.Spec.Workload.ExtraLabels
-	dep.Spec.Template.Labels = mergeLabels(map[string]string{"app": instance.Name}, instance.Spec.Workload.ExtraLabels)
-	return dep
+// reconcileDeployment applies the Deployment of the Ingress Controller.
+func (r *NginxIngressControllerReconciler) reconcileDeployment(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	dep, err := deploymentForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return err
+	}
+	if autoscalingEnabled(instance) {
+		if err := r.handOverReplicas(ctx, dep); err != nil {
+			log.Error(err, "Failed to hand over the replicas of the Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
+			return err
+		}
+	}
+	if err := r.apply(ctx, dep); err != nil {
+		log.Error(err, "Failed to apply Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,150 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+func newTestInstance() *v1beta1.NginxIngressController {
+	instance := &v1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+	}
+	instance.Default()
+	return instance
+}
+
+func TestDeploymentForNginxIngressController(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	instance := newTestInstance()
+	instance.Spec.Workload.NodeSelector = map[string]string{"node-role.kubernetes.io/edge": ""}
+	instance.Spec.Workload.Tolerations = []corev1.Toleration{{Key: "edge", Operator: corev1.TolerationOpExists}}
+	instance.Spec.Workload.HostNetwork = true
+
+	dep, err := deploymentForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatalf("deploymentForNginxIngressController() returned error %v", err)
+	}
+	spec := dep.Spec.Template.Spec
+	if !reflect.DeepEqual(spec.NodeSelector, instance.Spec.Workload.NodeSelector) ||
+		!reflect.DeepEqual(spec.Tolerations, instance.Spec.Workload.Tolerations) || !spec.HostNetwork {
+		t.Errorf("deploymentForNginxIngressController() did not render the scheduling spec: %v", spec)
+	}
+	if spec.DNSPolicy != corev1.DNSClusterFirstWithHostNet {
+		t.Errorf("deploymentForNginxIngressController() set dnsPolicy %v but expected %v", spec.DNSPolicy, corev1.DNSClusterFirstWithHostNet)
+	}
+	if dep.Spec.Replicas == nil || *dep.Spec.Replicas != 1 {
+		t.Errorf("deploymentForNginxIngressController() set replicas %v but expected 1", dep.Spec.Replicas)
+	}
+
+	instance.Spec.Autoscaling = &v1beta1.Autoscaling{Enable: true, MaxReplicas: 3}
+	dep, err = deploymentForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatalf("deploymentForNginxIngressController() returned error %v", err)
+	}
+	if dep.Spec.Replicas != nil {
+		t.Errorf("deploymentForNginxIngressController() set replicas %v while autoscaling is enabled", *dep.Spec.Replicas)
+	}
+}
+
+func TestPodTemplateExtensions(t *testing.T) {
+	instance := newTestInstance()
+	instance.Spec.Workload.ExtraEnv = []corev1.EnvVar{{Name: "TZ", Value: "UTC"}}
+	instance.Spec.Workload.ExtraVolumes = []corev1.Volume{{Name: "plugins"}}
+	instance.Spec.Workload.ExtraVolumeMounts = []corev1.VolumeMount{{Name: "plugins", MountPath: "/etc/nginx/lua/plugins"}}
+	instance.Spec.Workload.InitContainers = []corev1.Container{{Name: "fetch-plugins", Image: "busybox"}}
+	instance.Spec.Workload.ExtraContainers = []corev1.Container{{Name: "log-shipper", Image: "fluent-bit"}}
+
+	spec := podTemplateForNginxIngressController(instance).Spec
+	if len(spec.Containers) != 2 || spec.Containers[0].Name != instance.Name || spec.Containers[1].Name != "log-shipper" {
+		t.Errorf("podTemplateForNginxIngressController() rendered containers %v", spec.Containers)
+	}
+	if len(spec.InitContainers) != 1 || spec.InitContainers[0].Name != "fetch-plugins" {
+		t.Errorf("podTemplateForNginxIngressController() rendered init containers %v", spec.InitContainers)
+	}
+	container := spec.Containers[0]
+	if env := container.Env[len(container.Env)-1]; env.Name != "TZ" || len(container.Env) != 4 {
+		t.Errorf("podTemplateForNginxIngressController() rendered env %v", container.Env)
+	}
+	if mounts := container.VolumeMounts; mounts[len(mounts)-1].Name != "plugins" {
+		t.Errorf("podTemplateForNginxIngressController() rendered volume mounts %v", mounts)
+	}
+	if volumes := spec.Volumes; volumes[len(volumes)-1].Name != "plugins" {
+		t.Errorf("podTemplateForNginxIngressController() rendered volumes %v", volumes)
+	}
+
+	instance.Spec.Workload.ExtraEnv = []corev1.EnvVar{{Name: "LD_PRELOAD", Value: ""}}
+	instance.Spec.Workload.ExtraVolumes = []corev1.Volume{{Name: "webhook-cert"}}
+	instance.Spec.Workload.ExtraContainers = []corev1.Container{{Name: instance.Name}}
+	err := instance.ValidateSpec()
+	if agg, ok := err.(utilerrors.Aggregate); !ok || len(agg.Errors()) != 3 {
+		t.Errorf("ValidateSpec() returned %v but expected 3 errors", err)
+	}
+}
+
+func TestPodTemplateProbes(t *testing.T) {
+	instance := newTestInstance()
+	container := podTemplateForNginxIngressController(instance).Spec.Containers[0]
+	if p := container.LivenessProbe; p == nil || p.FailureThreshold != 5 || p.InitialDelaySeconds != 10 ||
+		p.HTTPGet == nil || p.HTTPGet.Path != "/healthz" || p.HTTPGet.Port.IntValue() != 10254 {
+		t.Errorf("podTemplateForNginxIngressController() rendered liveness probe %+v", p)
+	}
+	if p := container.ReadinessProbe; p == nil || p.FailureThreshold != 3 || p.HTTPGet == nil {
+		t.Errorf("podTemplateForNginxIngressController() rendered readiness probe %+v", p)
+	}
+	if container.StartupProbe != nil {
+		t.Errorf("podTemplateForNginxIngressController() rendered startup probe %+v but expected none", container.StartupProbe)
+	}
+
+	instance.Spec.Workload.StartupProbe = &corev1.Probe{FailureThreshold: 30, PeriodSeconds: 10}
+	instance.Spec.Workload.ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString("http")}},
+	}
+	container = podTemplateForNginxIngressController(instance).Spec.Containers[0]
+	if p := container.StartupProbe; p == nil || p.FailureThreshold != 30 || p.HTTPGet == nil || p.HTTPGet.Path != "/healthz" {
+		t.Errorf("podTemplateForNginxIngressController() rendered startup probe %+v", p)
+	}
+	if instance.Spec.Workload.StartupProbe.HTTPGet != nil {
+		t.Error("podTemplateForNginxIngressController() modified the startup probe of the spec")
+	}
+	if p := container.ReadinessProbe; p == nil || p.HTTPGet != nil || p.TCPSocket == nil {
+		t.Errorf("podTemplateForNginxIngressController() rendered readiness probe %+v", p)
+	}
+
+	// A partial override keeps the default handler and thresholds.
+	instance.Spec.Workload.LivenessProbe = &corev1.Probe{InitialDelaySeconds: 60}
+	if p := podTemplateForNginxIngressController(instance).Spec.Containers[0].LivenessProbe; p == nil ||
+		p.InitialDelaySeconds != 60 || p.FailureThreshold != 5 || p.HTTPGet == nil || p.HTTPGet.Path != "/healthz" {
+		t.Errorf("podTemplateForNginxIngressController() rendered liveness probe %+v for a partial override", p)
+	}
+
+	instance.Spec.Workload.StartupProbe.SuccessThreshold = 2
+	if err := instance.ValidateSpec(); err == nil {
+		t.Error("ValidateSpec() accepted a startup probe with successThreshold 2")
+	}
+}
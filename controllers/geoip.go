@@ -0,0 +1,99 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+// geoIPPath is where ingress-nginx downloads and reads the GeoIP2 databases.
+const geoIPPath = "/etc/ingress-controller/geoip"
+
+// geoIPArgs returns the flags of the Ingress Controller that download the GeoIP2 databases with a license key.
+func geoIPArgs(instance *v1beta1.NginxIngressController) []string {
+	geoip := instance.Spec.GeoIP
+	if geoip == nil || geoip.LicenseKeySecret == nil {
+		return nil
+	}
+	args := []string{"--maxmind-license-key=$(MAXMIND_LICENSE_KEY)"}
+	if len(geoip.EditionIDs) > 0 {
+		args = append(args, fmt.Sprintf("--maxmind-edition-ids=%s", strings.Join(geoip.EditionIDs, ",")))
+	}
+	return args
+}
+
+// addGeoIP adds the license key and the volume of the GeoIP2 databases to the pod template. The databases are
+// downloaded to an emptyDir volume when a license key is used.
+func addGeoIP(instance *v1beta1.NginxIngressController, template *corev1.PodTemplateSpec) {
+	geoip := instance.Spec.GeoIP
+	if geoip == nil {
+		return
+	}
+
+	container := &template.Spec.Containers[0]
+	volume := corev1.Volume{Name: "geoip"}
+	switch {
+	case geoip.LicenseKeySecret != nil:
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:      "MAXMIND_LICENSE_KEY",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: geoip.LicenseKeySecret},
+		})
+		volume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+	case geoip.Databases.PersistentVolumeClaim != "":
+		volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: geoip.Databases.PersistentVolumeClaim,
+			ReadOnly:  true,
+		}
+	default:
+		volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: geoip.Databases.ConfigMap},
+		}
+	}
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      volume.Name,
+		MountPath: geoIPPath,
+		ReadOnly:  geoip.LicenseKeySecret == nil,
+	})
+	template.Spec.Volumes = append(template.Spec.Volumes, volume)
+}
+
+// checkGeoIPLicenseKey returns an error if the Secret of the MaxMind license key does not exist or lacks the key,
+// so that the pods are not rolled out with a license key they cannot read.
+func (r *NginxIngressControllerReconciler) checkGeoIPLicenseKey(ctx context.Context, instance *v1beta1.NginxIngressController) error {
+	if instance.Spec.GeoIP == nil || instance.Spec.GeoIP.LicenseKeySecret == nil {
+		return nil
+	}
+	ref := instance.Spec.GeoIP.LicenseKeySecret
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: instance.Namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("GeoIP license key Secret %s/%s not found", instance.Namespace, ref.Name)
+		}
+		return err
+	}
+	if _, ok := secret.Data[ref.Key]; !ok {
+		return fmt.Errorf("GeoIP license key Secret %s/%s has no key %s", instance.Namespace, ref.Name, ref.Key)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+func TestGeoIPLicenseKey(t *testing.T) {
+	instance := newTestInstance()
+	instance.Spec.GeoIP = &v1beta1.GeoIP{
+		LicenseKeySecret: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "maxmind"},
+			Key:                  "license-key",
+		},
+		EditionIDs: []string{"GeoLite2-City", "GeoLite2-Country"},
+	}
+	if err := instance.ValidateSpec(); err != nil {
+		t.Fatalf("ValidateSpec() returned error %v", err)
+	}
+
+	args := generatePodArgs(instance)
+	for _, arg := range []string{"--maxmind-license-key=$(MAXMIND_LICENSE_KEY)", "--maxmind-edition-ids=GeoLite2-City,GeoLite2-Country"} {
+		if !containsStr(args, arg) {
+			t.Errorf("generatePodArgs() returned %v without %s", args, arg)
+		}
+	}
+	if data := configMapData(instance); data["use-geoip2"] != "true" {
+		t.Errorf("configMapData() returned %v without use-geoip2", data)
+	}
+
+	spec := podTemplateForNginxIngressController(instance).Spec
+	container := spec.Containers[0]
+	env := container.Env[len(container.Env)-1]
+	if env.Name != "MAXMIND_LICENSE_KEY" || env.ValueFrom == nil || !reflect.DeepEqual(env.ValueFrom.SecretKeyRef, instance.Spec.GeoIP.LicenseKeySecret) {
+		t.Errorf("podTemplateForNginxIngressController() rendered env %v", container.Env)
+	}
+	volume := spec.Volumes[len(spec.Volumes)-1]
+	if volume.Name != "geoip" || volume.EmptyDir == nil {
+		t.Errorf("podTemplateForNginxIngressController() rendered volume %v but expected an emptyDir", volume)
+	}
+	mount := container.VolumeMounts[len(container.VolumeMounts)-1]
+	if mount.MountPath != geoIPPath || mount.ReadOnly {
+		t.Errorf("podTemplateForNginxIngressController() rendered volume mount %v", mount)
+	}
+}
+
+func TestGeoIPDatabases(t *testing.T) {
+	instance := newTestInstance()
+	instance.Spec.GeoIP = &v1beta1.GeoIP{Databases: &v1beta1.GeoIPDatabases{PersistentVolumeClaim: "geoip"}}
+	if err := instance.ValidateSpec(); err != nil {
+		t.Fatalf("ValidateSpec() returned error %v", err)
+	}
+	for _, arg := range generatePodArgs(instance) {
+		if arg == "--maxmind-license-key=$(MAXMIND_LICENSE_KEY)" {
+			t.Errorf("generatePodArgs() set %s without a license key", arg)
+		}
+	}
+	spec := podTemplateForNginxIngressController(instance).Spec
+	volume := spec.Volumes[len(spec.Volumes)-1]
+	if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != "geoip" {
+		t.Errorf("podTemplateForNginxIngressController() rendered volume %v but expected the PersistentVolumeClaim", volume)
+	}
+
+	tests := []*v1beta1.GeoIP{
+		{},
+		{Databases: &v1beta1.GeoIPDatabases{}},
+		{Databases: &v1beta1.GeoIPDatabases{PersistentVolumeClaim: "geoip", ConfigMap: "geoip"}},
+		{Databases: &v1beta1.GeoIPDatabases{ConfigMap: "geoip"}, EditionIDs: []string{"GeoLite2-City"}},
+		{Databases: &v1beta1.GeoIPDatabases{ConfigMap: "geoip"}, LicenseKeySecret: &corev1.SecretKeySelector{}},
+	}
+	for _, geoip := range tests {
+		instance.Spec.GeoIP = geoip
+		if err := instance.ValidateSpec(); err == nil {
+			t.Errorf("ValidateSpec() accepted geoip %+v", geoip)
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"fmt"
 
 	networking "k8s.io/api/networking/v1"
@@ -33,5 +34,42 @@ func ingressClassForNginxIngressController(instance *v1beta1.NginxIngressControl
 			Controller: fmt.Sprintf("kubegems.io/ingress-nginx-%v", instance.Spec.IngressClass),
 		},
 	}
+	if spec := instance.Spec.IngressClassSpec; spec != nil {
+		ic.Labels = spec.ExtraLabels
+		ic.Spec.Parameters = spec.Parameters
+		if spec.Default {
+			ic.Annotations = map[string]string{networking.AnnotationIsDefaultIngressClass: "true"}
+		}
+	}
 	return ic
 }
+
+// defaultIngressClassOwner returns the older NginxIngressController that already has the default IngressClass when
+// the NginxIngressController asks for it, or nil, so that at most one of them is the default.
+func (r *NginxIngressControllerReconciler) defaultIngressClassOwner(ctx context.Context, instance *v1beta1.NginxIngressController) (*v1beta1.NginxIngressController, error) {
+	if !instance.IsDefaultIngressClass() {
+		return nil, nil
+	}
+	list := &v1beta1.NginxIngressControllerList{}
+	if err := r.List(ctx, list); err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		other := &list.Items[i]
+		if other.UID == instance.UID || !other.IsDefaultIngressClass() || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if olderThan(other, instance) {
+			return other, nil
+		}
+	}
+	return nil, nil
+}
+
+// olderThan orders NginxIngressControllers by creation time, then by namespace and name.
+func olderThan(a, b *v1beta1.NginxIngressController) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+}
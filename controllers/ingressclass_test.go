@@ -0,0 +1,86 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIngressClassForNginxIngressController(t *testing.T) {
+	instance := newTestInstance()
+	ic := ingressClassForNginxIngressController(instance)
+	if ic.Annotations != nil || ic.Labels != nil || ic.Spec.Parameters != nil {
+		t.Errorf("ingressClassForNginxIngressController() returned %v but expected a bare IngressClass", ic)
+	}
+
+	kind := "IngressParameters"
+	instance.Spec.IngressClassSpec = &v1beta1.IngressClassSpec{
+		Default:     true,
+		ExtraLabels: map[string]string{"team": "web"},
+		Parameters:  &networking.IngressClassParametersReference{Kind: kind, Name: "nginx"},
+	}
+	ic = ingressClassForNginxIngressController(instance)
+	if ic.Annotations[networking.AnnotationIsDefaultIngressClass] != "true" {
+		t.Errorf("ingressClassForNginxIngressController() returned annotations %v but expected the default class annotation", ic.Annotations)
+	}
+	if !reflect.DeepEqual(ic.Labels, instance.Spec.IngressClassSpec.ExtraLabels) || !reflect.DeepEqual(ic.Spec.Parameters, instance.Spec.IngressClassSpec.Parameters) {
+		t.Errorf("ingressClassForNginxIngressController() returned %v but expected the labels and parameters of %v", ic, instance.Spec.IngressClassSpec)
+	}
+}
+
+func TestDefaultIngressClassOwner(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	newDefault := func(name string, created time.Time) *v1beta1.NginxIngressController {
+		instance := newTestInstance()
+		instance.Name = name
+		instance.UID = types.UID(name)
+		instance.CreationTimestamp = metav1.NewTime(created)
+		instance.Spec.IngressClassSpec = &v1beta1.IngressClassSpec{Default: true}
+		return instance
+	}
+	now := time.Now().Truncate(time.Second)
+	older := newDefault("older", now.Add(-time.Hour))
+	newer := newDefault("newer", now)
+	r := &NginxIngressControllerReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(older, newer).Build(),
+		Scheme: scheme,
+	}
+
+	owner, err := r.defaultIngressClassOwner(context.TODO(), newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner == nil || owner.Name != older.Name {
+		t.Errorf("defaultIngressClassOwner() returned %v but expected %s", owner, older.Name)
+	}
+	if owner, err := r.defaultIngressClassOwner(context.TODO(), older); err != nil || owner != nil {
+		t.Errorf("defaultIngressClassOwner() returned %v, %v but expected no owner for the oldest instance", owner, err)
+	}
+}
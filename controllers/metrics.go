@@ -0,0 +1,161 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var serviceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+
+func metricsEnabled(instance *v1beta1.NginxIngressController) bool {
+	return instance.Spec.Metrics != nil && instance.Spec.Metrics.Enable
+}
+
+func serviceMonitorEnabled(instance *v1beta1.NginxIngressController) bool {
+	return metricsEnabled(instance) && instance.Spec.Metrics.ServiceMonitor != nil && instance.Spec.Metrics.ServiceMonitor.Enable
+}
+
+// metricsPort returns the port where the Prometheus metrics and the health checks are exposed.
+func metricsPort(instance *v1beta1.NginxIngressController) int32 {
+	if instance.Spec.Metrics != nil && instance.Spec.Metrics.Port != nil {
+		return int32(*instance.Spec.Metrics.Port)
+	}
+	return v1beta1.DefaultMetricsPort
+}
+
+func metricsServiceName(instance *v1beta1.NginxIngressController) string {
+	return instance.Name + "-metrics"
+}
+
+func metricsLabels(instance *v1beta1.NginxIngressController) map[string]string {
+	return map[string]string{"app": instance.Name, "app.kubernetes.io/component": "metrics"}
+}
+
+// reconcileMetrics applies the metrics Service and the ServiceMonitor when the metrics are enabled and removes them otherwise.
+func (r *NginxIngressControllerReconciler) reconcileMetrics(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	key := types.NamespacedName{Name: metricsServiceName(instance), Namespace: instance.Namespace}
+
+	if !metricsEnabled(instance) {
+		if err := r.deleteIfOwned(ctx, log, key, &corev1.Service{}, instance); err != nil {
+			return err
+		}
+	} else {
+		svc, err := metricsServiceForNginxIngressController(instance, r.Scheme)
+		if err != nil {
+			return err
+		}
+		if err := r.apply(ctx, svc); err != nil {
+			log.Error(err, "Failed to apply metrics Service")
+			return err
+		}
+	}
+
+	// The ServiceMonitor CRD is optional, skip it when prometheus-operator is not installed.
+	if _, err := r.RESTMapper().RESTMapping(serviceMonitorGVK.GroupKind(), serviceMonitorGVK.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			if serviceMonitorEnabled(instance) {
+				log.Info("ServiceMonitor CRD not found, skipping ServiceMonitor creation")
+			}
+			return nil
+		}
+		return err
+	}
+
+	if !serviceMonitorEnabled(instance) {
+		sm := &unstructured.Unstructured{}
+		sm.SetGroupVersionKind(serviceMonitorGVK)
+		return r.deleteIfOwned(ctx, log, key, sm, instance)
+	}
+	sm, err := serviceMonitorForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return err
+	}
+	if err := r.apply(ctx, sm); err != nil {
+		log.Error(err, "Failed to apply ServiceMonitor")
+		return err
+	}
+	return nil
+}
+
+func metricsServiceForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*corev1.Service, error) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      metricsServiceName(instance),
+			Namespace: instance.Namespace,
+			Labels:    metricsLabels(instance),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": instance.Name},
+			Type:     corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "metrics",
+					Port:       metricsPort(instance),
+					Protocol:   corev1.ProtocolTCP,
+					TargetPort: intstr.FromString("metrics"),
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(instance, svc, scheme); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+func serviceMonitorForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*unstructured.Unstructured, error) {
+	monitor := instance.Spec.Metrics.ServiceMonitor
+	sm := &unstructured.Unstructured{}
+	sm.SetGroupVersionKind(serviceMonitorGVK)
+	sm.SetName(metricsServiceName(instance))
+	sm.SetNamespace(instance.Namespace)
+	sm.SetLabels(mergeLabels(metricsLabels(instance), monitor.ExtraLabels))
+
+	endpoint := map[string]interface{}{"port": "metrics", "path": "/metrics"}
+	if monitor.Interval != "" {
+		endpoint["interval"] = monitor.Interval
+	}
+	selector := map[string]interface{}{}
+	for k, v := range metricsLabels(instance) {
+		selector[k] = v
+	}
+	spec := map[string]interface{}{
+		"selector":          map[string]interface{}{"matchLabels": selector},
+		"namespaceSelector": map[string]interface{}{"matchNames": []interface{}{instance.Namespace}},
+		"endpoints":         []interface{}{endpoint},
+	}
+	if err := unstructured.SetNestedMap(sm.Object, spec, "spec"); err != nil {
+		return nil, err
+	}
+	if err := ctrl.SetControllerReference(instance, sm, scheme); err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
@@ -20,11 +20,16 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"sort"
 
 	"github.com/go-logr/logr"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,29 +39,43 @@ import (
 	"kubegems.io/ingress-nginx-operator/api/v1beta1"
 	networkingv1beta1 "kubegems.io/ingress-nginx-operator/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // NginxIngressControllerReconciler reconciles a NginxIngressController object
 type NginxIngressControllerReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	// APIReader reads the objects that are not cached by the Client, e.g. ReplicaSets.
+	APIReader client.Reader
+	Scheme    *runtime.Scheme
 }
 
 const (
-	clusterRoleName = "ingress-nginx-role"
-	finalizer       = "nginxingresscontroller.networking.kubegems.io/finalizer"
+	clusterRoleName             = "ingress-nginx-role"
+	ingressClassClusterRoleName = "ingress-nginx-ingressclass-role"
+	finalizer                   = "nginxingresscontroller.networking.kubegems.io/finalizer"
 )
 
 //+kubebuilder:rbac:groups=networking.kubegems.io,resources=nginxingresscontrollers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.kubegems.io,resources=nginxingresscontrollers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.kubegems.io,resources=nginxingresscontrollers/finalizers,verbs=update
 
-//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingressclasses;ingresses;ingresses/status,verbs=get;create;delete;list;watch;update
-//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments;daemonsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingressclasses;ingresses;ingresses/status,verbs=get;create;delete;list;watch;update;patch
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings;roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update
 //+kubebuilder:rbac:groups="",resources=services;endpoints;pods;secrets;events;configmaps;serviceaccounts;namespaces,verbs=create;update;get;list;watch;patch;delete
 
@@ -123,149 +142,101 @@ func (r *NginxIngressControllerReconciler) Reconcile(ctx context.Context, req ct
 	}
 
 	if err := addDefaultFields(instance); err != nil {
+		log.Error(err, "Invalid NginxIngressController spec")
+		if statusErr := r.updateStatus(ctx, instance, err, nil); statusErr != nil {
+			log.Error(statusErr, "Failed to update NginxIngressController status")
+		}
 		return ctrl.Result{}, err
 	}
 
-	if err := r.createCommonResources(log); err != nil {
-		return ctrl.Result{}, err
+	reconcileErr := r.reconcileResources(ctx, log, instance)
+	if err := r.updateStatus(ctx, instance, nil, reconcileErr); err != nil {
+		log.Error(err, "Failed to update NginxIngressController status")
+		if reconcileErr == nil {
+			return ctrl.Result{}, err
+		}
 	}
-
-	err = r.checkPrerequisites(log, instance)
-	if err != nil {
-		return ctrl.Result{}, err
+	if reconcileErr != nil {
+		return ctrl.Result{}, reconcileErr
 	}
+	log.Info("Reconciliation finished")
+	return ctrl.Result{}, nil
+}
 
-	// deployment
-	found := &appsv1.Deployment{}
-	dep, err := deploymentForNginxIngressController(instance, r.Scheme)
-	if err != nil {
-		return ctrl.Result{}, err
+// reconcileResources creates or updates all the objects of the Ingress Controller.
+func (r *NginxIngressControllerReconciler) reconcileResources(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	if err := r.createCommonResources(log); err != nil {
+		return err
 	}
-	err = r.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, found)
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("Creating a new Deployment for NGINX Ingress Controller", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
 
-		err = r.Create(ctx, dep)
-		if err != nil {
-			log.Error(err, "Failed to create new Deployment", "Deployment.Namespace", dep.Namespace, "Deployment.Name", dep.Name)
-			return ctrl.Result{}, err
-		}
-	} else if err != nil {
-		log.Error(err, "Failed to get Deployment")
-		return ctrl.Result{}, err
-	} else if hasDeploymentChanged(found, instance) {
-		log.Info("NginxIngressController spec has changed, updating Deployment")
-		updated := updateDeployment(found, instance)
-		err = r.Update(ctx, updated)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-	}
-	svc := &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      instance.Name,
-			Namespace: instance.Namespace,
-		},
+	if err := r.checkPrerequisites(log, instance); err != nil {
+		return err
 	}
-	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, svc, serviceMutateFn(svc, instance, r.Scheme)); err != nil {
-		log.Error(err, "Failed to create or update Service")
-		return ctrl.Result{}, err
+
+	if err := r.reconcileAdmissionWebhook(ctx, log, instance); err != nil {
+		return err
 	}
-	cm := &v1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      instance.Name,
-			Namespace: instance.Namespace,
-		},
+
+	if err := r.reconcileDefaultTLS(ctx, log, instance); err != nil {
+		return err
 	}
-	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, configMapMutateFn(cm, instance, r.Scheme)); err != nil {
-		log.Error(err, "Failed to create or update ConfigMap")
-		return ctrl.Result{}, err
+
+	if err := r.reconcileDefaultBackend(ctx, log, instance); err != nil {
+		return err
 	}
 
-	if !instance.Status.Deployed {
-		instance.Status.Deployed = true
-		if err := r.Status().Update(ctx, instance); err != nil {
-			return ctrl.Result{}, err
-		}
+	if err := r.checkGeoIPLicenseKey(ctx, instance); err != nil {
+		return err
 	}
-	log.Info("Reconciliation finished")
-	return ctrl.Result{}, nil
-}
 
-func configMapMutateFn(cm *v1.ConfigMap, instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) controllerutil.MutateFn {
-	return func() error {
-		cm.Data = instance.Spec.ConfigMapData
-		return ctrl.SetControllerReference(instance, cm, scheme)
+	if err := r.reconcileWorkload(ctx, log, instance); err != nil {
+		return err
 	}
-}
 
-func addDefaultFields(in *v1beta1.NginxIngressController) error {
-	if in.Spec.Image.Repository == "" {
-		in.Spec.Image.Repository = "registry.k8s.io/ingress-nginx/controller"
+	if err := r.reconcileCanary(ctx, log, instance); err != nil {
+		return err
 	}
-	if in.Spec.Image.Tag == "" {
-		in.Spec.Image.Tag = "v1.3.0"
+	if err := r.reconcileService(ctx, log, instance); err != nil {
+		return err
 	}
-	if in.Spec.Image.PullPolicy == "" {
-		in.Spec.Image.PullPolicy = v1.PullIfNotPresent
+	cm, err := configMapForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return err
 	}
-	if !containsStr([]string{"Always", "IfNotPresent", "Never"}, string(in.Spec.Image.PullPolicy)) {
-		return fmt.Errorf("image pull policy %s not valid", in.Spec.Image.PullPolicy)
+	if err := r.apply(ctx, cm); err != nil {
+		log.Error(err, "Failed to apply ConfigMap")
+		return err
 	}
 
-	if in.Spec.Replicas == nil {
-		var r int32 = 1
-		in.Spec.Replicas = &r
+	if err := r.reconcileProxiedServices(ctx, log, instance); err != nil {
+		return err
 	}
 
-	if in.Spec.Service == nil {
-		in.Spec.Service = &networkingv1beta1.Service{}
-	}
-	if in.Spec.Service.Type == "" {
-		in.Spec.Service.Type = "NodePort"
-	}
-	if !containsStr([]string{"NodePort", "LoadBanlancer"}, in.Spec.Service.Type) {
-		return fmt.Errorf("service type %s not valid", in.Spec.Service.Type)
+	if err := r.reconcileAutoscaling(ctx, log, instance); err != nil {
+		return err
 	}
 
-	if in.Spec.Workload == nil {
-		in.Spec.Workload = &networkingv1beta1.Workload{}
+	if err := r.reconcilePodDisruptionBudget(ctx, log, instance); err != nil {
+		return err
 	}
 
-	if in.Spec.IngressClass == "" {
-		in.Spec.IngressClass = "nginx"
-	}
-	return nil
+	return r.reconcileMetrics(ctx, log, instance)
 }
 
-// createIfNotExists creates a new object. If the object exists, does nothing. It returns whether the object existed before or not.
-func (r *NginxIngressControllerReconciler) createIfNotExists(object client.Object) (bool, error) {
-	err := r.Create(context.TODO(), object)
-	if err != nil && errors.IsAlreadyExists(err) {
-		return true, nil
-	}
-	return false, err
+// addDefaultFields applies the defaults of the NginxIngressController and validates its spec.
+// The admission webhooks do the same, but they may be disabled. The images that do not support the cluster do not
+// fail the reconciliation, they are not rolled out.
+func addDefaultFields(in *v1beta1.NginxIngressController) error {
+	in.Default()
+	return in.ValidateSpec()
 }
 
 func (r *NginxIngressControllerReconciler) finalizeNginxIngressController(log logr.Logger, instance *v1beta1.NginxIngressController) error {
-	crb := clusterRoleBindingForNginxIngressController(clusterRoleName)
-
-	err := r.Get(context.TODO(), types.NamespacedName{Name: clusterRoleName, Namespace: v1.NamespaceAll}, crb)
-	if err != nil {
+	if err := r.updateSharedClusterRoleBinding(context.TODO(), instance, false); err != nil {
 		return err
 	}
 
-	var subjects []rbacv1.Subject
-	for _, s := range crb.Subjects {
-		if s.Name != instance.Name || s.Namespace != instance.Namespace {
-			subjects = append(subjects, s)
-		}
-	}
-
-	crb.Subjects = subjects
-
-	err = r.Update(context.TODO(), crb)
-	if err != nil {
+	if err := r.deleteNamespacedRBAC(context.TODO(), log, instance, nil); err != nil {
 		return err
 	}
 
@@ -276,52 +247,184 @@ func (r *NginxIngressControllerReconciler) finalizeNginxIngressController(log lo
 		}
 	}
 
+	if err := r.deleteValidatingWebhookConfiguration(context.TODO(), instance); err != nil {
+		return err
+	}
+
 	log.Info("Successfully finalized NginxIngressController")
 	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NginxIngressControllerReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	isCommonResource := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetName() == clusterRoleName || object.GetName() == ingressClassClusterRoleName
+	})
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&networkingv1beta1.NginxIngressController{}).
-		Complete(r)
+		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.DaemonSet{}).
+		Owns(&corev1.Service{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &rbacv1.ClusterRole{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForAllInstances),
+			builder.WithPredicates(isCommonResource)).
+		Watches(&source.Kind{Type: &rbacv1.ClusterRoleBinding{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForClusterRoleBinding)).
+		Watches(&source.Kind{Type: &rbacv1.Role{}},
+			handler.EnqueueRequestsFromMapFunc(requestForInstanceLabels)).
+		Watches(&source.Kind{Type: &rbacv1.RoleBinding{}},
+			handler.EnqueueRequestsFromMapFunc(requestForInstanceLabels)).
+		Watches(&source.Kind{Type: &networking.IngressClass{}},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForIngressClass)).
+		Watches(&source.Kind{Type: &admissionregistrationv1.ValidatingWebhookConfiguration{}},
+			handler.EnqueueRequestsFromMapFunc(requestForInstanceLabels))
+	// Older versions of k8s do not serve the APIs of the optional objects, whose informers would never sync.
+	if runningK8sVersionAtLeast(autoscalingV2MinK8sVersion) {
+		b = b.Owns(&autoscalingv2.HorizontalPodAutoscaler{})
+	}
+	if runningK8sVersionAtLeast(policyV1MinK8sVersion) {
+		b = b.Owns(&policyv1.PodDisruptionBudget{})
+	}
+	return b.Complete(r)
 }
 
-func serviceMutateFn(svc *corev1.Service, instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) controllerutil.MutateFn {
+// requestsForAllInstances maps a resource shared by all the Ingress Controllers to a request for each NginxIngressController.
+func (r *NginxIngressControllerReconciler) requestsForAllInstances(object client.Object) []reconcile.Request {
+	return r.requestsForInstances(func(*v1beta1.NginxIngressController) bool { return true })
+}
+
+// requestsForClusterRoleBinding maps the shared ClusterRoleBinding to requests for all the NginxIngressControllers
+// and a per-instance ClusterRoleBinding to a request for its NginxIngressController.
+func (r *NginxIngressControllerReconciler) requestsForClusterRoleBinding(object client.Object) []reconcile.Request {
+	if object.GetName() == clusterRoleName {
+		return r.requestsForAllInstances(object)
+	}
+	return requestForInstanceLabels(object)
+}
+
+// requestsForIngressClass maps an IngressClass to a request for the NginxIngressControllers of that class.
+func (r *NginxIngressControllerReconciler) requestsForIngressClass(object client.Object) []reconcile.Request {
+	return r.requestsForInstances(func(instance *v1beta1.NginxIngressController) bool {
+		class := instance.Spec.IngressClass
+		if class == "" {
+			class = "nginx"
+		}
+		return class == object.GetName()
+	})
+}
+
+func (r *NginxIngressControllerReconciler) requestsForInstances(match func(*v1beta1.NginxIngressController) bool) []reconcile.Request {
+	list := &v1beta1.NginxIngressControllerList{}
+	if err := r.List(context.TODO(), list); err != nil {
+		ctrl.Log.WithName("nginxingresscontroller").Error(err, "Failed to list NginxIngressControllers")
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range list.Items {
+		if match(&list.Items[i]) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+		}
+	}
+	return requests
+}
+
+func serviceForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*corev1.Service, error) {
 	service := instance.Spec.Service
 	if service == nil {
 		service = &v1beta1.Service{}
 	}
-	labels := instance.Labels
-	annotations := instance.Annotations
+	labels := map[string]string{}
+	annotations := map[string]string{}
+	maps.Copy(labels, instance.Labels)
 	maps.Copy(labels, service.ExtraLabels)
+	maps.Copy(annotations, instance.Annotations)
 	maps.Copy(annotations, service.ExtraAnnotations)
-	selector := map[string]string{"app": instance.Name}
-	return func() error {
-		svc.Labels = labels
-		svc.Annotations = annotations
-		svc.Spec.Selector = selector
-		svc.Spec.Type = corev1.ServiceType(service.Type)
-		svc.Spec.Ports = mergePorts(svc.Spec.Ports, service.Ports)
-		return ctrl.SetControllerReference(instance, svc, scheme)
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        instance.Name,
+			Namespace:   instance.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{instanceNameLabel: instance.Name},
+			Type:     corev1.ServiceType(service.Type),
+			Ports:    mergePorts(append(defaultServicePorts(instance), proxiedServicePorts(instance)...), service.Ports),
+		},
 	}
+	if err := ctrl.SetControllerReference(instance, svc, scheme); err != nil {
+		return nil, err
+	}
+	return svc, nil
 }
 
-func mergePorts(cur, desired []corev1.ServicePort) []corev1.ServicePort {
-	ports := map[string]corev1.ServicePort{}
-	for _, port := range cur {
-		ports[port.Name] = port
+// reconcileService applies the Service of the Ingress Controller. The Service selects the pods of the workload and of
+// the canary by the instance label once all the pods of the workload carry it. Until then it keeps selecting them by
+// the app label, so that the pods created by earlier versions of the Operator keep receiving traffic.
+func (r *NginxIngressControllerReconciler) reconcileService(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	svc, err := serviceForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return err
 	}
-	// Ensure that the default ports are present
-	if httpport := ports["http"]; httpport.Name == "" {
-		ports["http"] = corev1.ServicePort{
-			Name: "http", Port: 80, TargetPort: intstr.IntOrString{IntVal: 80},
-		}
+	labeled, err := r.serviceSelectsInstanceLabel(ctx, instance)
+	if err != nil {
+		return err
 	}
-	if httpsport := ports["https"]; httpsport.Name == "" {
-		ports["https"] = corev1.ServicePort{
-			Name: "https", Port: 443, TargetPort: intstr.IntOrString{IntVal: 443},
+	if !labeled {
+		svc.Spec.Selector = map[string]string{"app": instance.Name}
+	}
+	if err := r.apply(ctx, svc); err != nil {
+		log.Error(err, "Failed to apply Service")
+		return err
+	}
+	return nil
+}
+
+// serviceSelectsInstanceLabel returns true if the Service already selects the instance label, or if all the pods of
+// the workload carry it.
+func (r *NginxIngressControllerReconciler) serviceSelectsInstanceLabel(ctx context.Context, instance *v1beta1.NginxIngressController) (bool, error) {
+	key := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+	current := &corev1.Service{}
+	if err := r.Get(ctx, key, current); err != nil && !errors.IsNotFound(err) {
+		return false, err
+	} else if err == nil && current.Spec.Selector[instanceNameLabel] == instance.Name {
+		return true, nil
+	}
+
+	if instance.Spec.Workload.Kind == v1beta1.WorkloadKindDaemonSet {
+		ds := &appsv1.DaemonSet{}
+		if err := r.Get(ctx, key, ds); err != nil {
+			return false, client.IgnoreNotFound(err)
 		}
+		return ds.Spec.Template.Labels[instanceNameLabel] == instance.Name && ds.Status.ObservedGeneration >= ds.Generation &&
+			ds.Status.UpdatedNumberScheduled == ds.Status.DesiredNumberScheduled &&
+			ds.Status.CurrentNumberScheduled == ds.Status.DesiredNumberScheduled, nil
+	}
+	dep := &appsv1.Deployment{}
+	if err := r.Get(ctx, key, dep); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return dep.Spec.Template.Labels[instanceNameLabel] == instance.Name && dep.Status.ObservedGeneration >= dep.Generation &&
+		dep.Status.UpdatedReplicas == dep.Status.Replicas, nil
+}
+
+// defaultServicePorts returns the ports always present in the Service of the Ingress Controller, targeting the ports
+// nginx listens on.
+func defaultServicePorts(instance *v1beta1.NginxIngressController) []corev1.ServicePort {
+	return []corev1.ServicePort{
+		{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(int(httpPort(instance)))},
+		{Name: "https", Port: 443, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt(int(httpsPort(instance)))},
+	}
+}
+
+// mergePorts merges the desired ports into the base ports by name, sorted by name.
+func mergePorts(base, desired []corev1.ServicePort) []corev1.ServicePort {
+	ports := map[string]corev1.ServicePort{}
+	for _, port := range base {
+		ports[port.Name] = port
 	}
 	for _, desired := range desired {
 		if port, ok := ports[desired.Name]; ok {
@@ -332,8 +435,12 @@ func mergePorts(cur, desired []corev1.ServicePort) []corev1.ServicePort {
 	}
 	result := make([]corev1.ServicePort, 0, len(ports))
 	for _, port := range ports {
+		if port.Protocol == "" {
+			port.Protocol = corev1.ProtocolTCP
+		}
 		result = append(result, port)
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
 	return result
 }
 
@@ -0,0 +1,79 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// policyV1MinK8sVersion is the first version of k8s serving policy/v1.
+const policyV1MinK8sVersion = "1.21"
+
+// reconcilePodDisruptionBudget applies the PodDisruptionBudget when it is set in the spec and removes it otherwise.
+func (r *NginxIngressControllerReconciler) reconcilePodDisruptionBudget(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	if !runningK8sVersionAtLeast(policyV1MinK8sVersion) {
+		if instance.Spec.PodDisruptionBudget != nil {
+			return fmt.Errorf("a PodDisruptionBudget requires Kubernetes %s or later, running %s", policyV1MinK8sVersion, RunningK8sVersion)
+		}
+		return nil
+	}
+	if instance.Spec.PodDisruptionBudget == nil {
+		key := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+		return r.deleteIfOwned(ctx, log, key, &policyv1.PodDisruptionBudget{}, instance)
+	}
+
+	pdb, err := podDisruptionBudgetForNginxIngressController(instance, r.Scheme)
+	if err != nil {
+		return err
+	}
+	if err := r.apply(ctx, pdb); err != nil {
+		log.Error(err, "Failed to apply PodDisruptionBudget")
+		return err
+	}
+	return nil
+}
+
+func podDisruptionBudgetForNginxIngressController(instance *v1beta1.NginxIngressController, scheme *runtime.Scheme) (*policyv1.PodDisruptionBudget, error) {
+	budget := instance.Spec.PodDisruptionBudget
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name,
+			Namespace: instance.Namespace,
+			Labels:    instance.Spec.Workload.ExtraLabels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": instance.Name},
+			},
+			MinAvailable:   budget.MinAvailable,
+			MaxUnavailable: budget.MaxUnavailable,
+		},
+	}
+	if err := ctrl.SetControllerReference(instance, pdb, scheme); err != nil {
+		return nil, err
+	}
+	return pdb, nil
+}
@@ -19,15 +19,20 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"kubegems.io/ingress-nginx-operator/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // checkPrerequisites creates all necessary objects before the deployment of a new Ingress Controller.
@@ -36,50 +41,28 @@ func (r *NginxIngressControllerReconciler) checkPrerequisites(log logr.Logger, i
 	if err != nil {
 		return err
 	}
-	existed, err := r.createIfNotExists(sa)
-	if err != nil {
-		return err
+	if err := r.apply(context.TODO(), sa); err != nil {
+		return fmt.Errorf("error applying ServiceAccount: %w", err)
 	}
 
-	if !existed {
-		log.Info("ServiceAccount created", "ServiceAccount.Namespace", sa.Namespace, "ServiceAccount.Name", sa.Name)
-	}
-
-	// Assign this new ServiceAccount to the ClusterRoleBinding (if is not present already)
-	crb := clusterRoleBindingForNginxIngressController(clusterRoleName)
-
-	err = r.Get(context.TODO(), types.NamespacedName{Name: clusterRoleName}, crb)
-	if err != nil {
+	if err := r.reconcileRBAC(context.TODO(), log, instance); err != nil {
 		return err
 	}
 
-	subject := subjectForServiceAccount(sa.Namespace, sa.Name)
-	found := false
-	for _, s := range crb.Subjects {
-		if s.Name == subject.Name && s.Namespace == subject.Namespace {
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		crb.Subjects = append(crb.Subjects, subject)
-
-		err = r.Update(context.TODO(), crb)
-		if err != nil {
-			return err
-		}
-	}
-
 	// IngressClass is available from k8s 1.18+
 	ic := ingressClassForNginxIngressController(instance)
-	existed, err = r.createIfNotExists(ic)
+	// The IngressClass is not the default while an older instance has the default class, the conflict is reported in
+	// the status.
+	owner, err := r.defaultIngressClassOwner(context.TODO(), instance)
 	if err != nil {
 		return err
 	}
-
-	if !existed {
-		log.Info("IngressClass created", "IngressClass.Name", ic.Name)
+	if owner != nil {
+		log.Info("The default IngressClass belongs to another NginxIngressController", "NginxIngressController.Namespace", owner.Namespace, "NginxIngressController.Name", owner.Name)
+		delete(ic.Annotations, networking.AnnotationIsDefaultIngressClass)
+	}
+	if err := r.apply(context.TODO(), ic); err != nil {
+		return fmt.Errorf("error applying IngressClass: %w", err)
 	}
 
 	return nil
@@ -100,28 +83,15 @@ func (r *NginxIngressControllerReconciler) createCommonResources(log logr.Logger
 	// Create ClusterRole and ClusterRoleBinding for all the NginxIngressController resources.
 	var err error
 
+	// For updates in the ClusterRole permissions (eg new CRDs of the Ingress Controller).
 	cr := clusterRoleForNginxIngressController(clusterRoleName)
+	if err = r.apply(context.TODO(), cr); err != nil {
+		return fmt.Errorf("error applying ClusterRole: %w", err)
+	}
 
-	err = r.Get(context.TODO(), types.NamespacedName{Name: clusterRoleName}, cr)
-
-	if err != nil {
-		if errors.IsNotFound(err) {
-			log.Info("no previous ClusterRole found, creating a new one.")
-			err = r.Create(context.TODO(), cr)
-			if err != nil {
-				return fmt.Errorf("error creating ClusterRole: %w", err)
-			}
-		} else {
-			return fmt.Errorf("error getting ClusterRole: %w", err)
-		}
-	} else {
-		// For updates in the ClusterRole permissions (eg new CRDs of the Ingress Controller).
-		log.Info("previous ClusterRole found, updating.")
-		cr := clusterRoleForNginxIngressController(clusterRoleName)
-		err = r.Update(context.TODO(), cr)
-		if err != nil {
-			return fmt.Errorf("error updating ClusterRole: %w", err)
-		}
+	icr := clusterRoleForIngressClasses(ingressClassClusterRoleName)
+	if err = r.apply(context.TODO(), icr); err != nil {
+		return fmt.Errorf("error applying ClusterRole: %w", err)
 	}
 
 	crb := clusterRoleBindingForNginxIngressController(clusterRoleName)
@@ -138,3 +108,101 @@ func (r *NginxIngressControllerReconciler) createCommonResources(log logr.Logger
 
 	return nil
 }
+
+// reconcileRBAC grants the permissions of the Ingress Controller. An Ingress Controller that watches all namespaces,
+// several namespaces or namespaces selected by label is a subject of the shared ClusterRoleBinding, as it lists the
+// resources of all the namespaces. One that watches a single namespace gets its own Roles and
+// RoleBindings, plus a ClusterRoleBinding restricted to the IngressClasses.
+func (r *NginxIngressControllerReconciler) reconcileRBAC(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	if singleWatchNamespace(instance) == "" {
+		if err := r.updateSharedClusterRoleBinding(ctx, instance, true); err != nil {
+			return err
+		}
+		return r.deleteNamespacedRBAC(ctx, log, instance, nil)
+	}
+
+	var keep []client.Object
+	for _, role := range rolesForNginxIngressController(instance) {
+		if err := r.apply(ctx, role); err != nil {
+			return fmt.Errorf("error applying Role: %w", err)
+		}
+		rb := roleBindingForRole(instance, role)
+		if err := r.apply(ctx, rb); err != nil {
+			return fmt.Errorf("error applying RoleBinding: %w", err)
+		}
+		keep = append(keep, role, rb)
+	}
+
+	crb := clusterRoleBindingForIngressClasses(instance)
+	if err := r.apply(ctx, crb); err != nil {
+		return fmt.Errorf("error applying ClusterRoleBinding: %w", err)
+	}
+	keep = append(keep, crb)
+
+	if err := r.deleteNamespacedRBAC(ctx, log, instance, keep); err != nil {
+		return err
+	}
+	return r.updateSharedClusterRoleBinding(ctx, instance, false)
+}
+
+// updateSharedClusterRoleBinding adds or removes the ServiceAccount of the Ingress Controller from the subjects of the
+// shared ClusterRoleBinding. The subjects are shared by all the Ingress Controllers, so they are updated instead of
+// applied.
+func (r *NginxIngressControllerReconciler) updateSharedClusterRoleBinding(ctx context.Context, instance *v1beta1.NginxIngressController, add bool) error {
+	crb := clusterRoleBindingForNginxIngressController(clusterRoleName)
+	if err := r.Get(ctx, types.NamespacedName{Name: clusterRoleName}, crb); err != nil {
+		return err
+	}
+
+	subject := subjectForServiceAccount(instance.Namespace, instance.Name)
+	var subjects []rbacv1.Subject
+	found := false
+	for _, s := range crb.Subjects {
+		if s.Name == subject.Name && s.Namespace == subject.Namespace {
+			found = true
+			continue
+		}
+		subjects = append(subjects, s)
+	}
+
+	switch {
+	case add && !found:
+		crb.Subjects = append(crb.Subjects, subject)
+	case !add && found:
+		crb.Subjects = subjects
+	default:
+		return nil
+	}
+	return r.Update(ctx, crb)
+}
+
+// deleteNamespacedRBAC deletes the per-instance Roles, RoleBindings and ClusterRoleBindings of the Ingress Controller
+// except the ones to keep, e.g. after the watched namespace changed.
+func (r *NginxIngressControllerReconciler) deleteNamespacedRBAC(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController, keep []client.Object) error {
+	kept := map[string]bool{}
+	for _, object := range keep {
+		kept[fmt.Sprintf("%T/%s", object, client.ObjectKeyFromObject(object))] = true
+	}
+
+	lists := []client.ObjectList{&rbacv1.RoleList{}, &rbacv1.RoleBindingList{}, &rbacv1.ClusterRoleBindingList{}}
+	for _, list := range lists {
+		if err := r.List(ctx, list, client.MatchingLabels(instanceLabels(instance))); err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			object, ok := item.(client.Object)
+			if !ok || kept[fmt.Sprintf("%T/%s", object, client.ObjectKeyFromObject(object))] {
+				continue
+			}
+			log.Info("Deleting stale RBAC object of NGINX Ingress Controller", "Kind", reflect.TypeOf(object).Elem().Name(), "Namespace", object.GetNamespace(), "Name", object.GetName())
+			if err := client.IgnoreNotFound(r.Delete(ctx, object)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// proxiedServices returns the TCP or UDP services of the Ingress Controller.
+func proxiedServices(instance *v1beta1.NginxIngressController, protocol corev1.Protocol) []v1beta1.ProxiedService {
+	if protocol == corev1.ProtocolUDP {
+		return instance.Spec.UDPServices
+	}
+	return instance.Spec.TCPServices
+}
+
+// proxiedServicesName returns the name of the ConfigMap of the TCP or UDP services, e.g. mynginx-tcp-services.
+func proxiedServicesName(instance *v1beta1.NginxIngressController, protocol corev1.Protocol) string {
+	return fmt.Sprintf("%s-%s-services", instance.Name, strings.ToLower(string(protocol)))
+}
+
+// proxiedPortName returns the name of the container and Service port of a TCP or UDP service, e.g. tcp-5432.
+func proxiedPortName(protocol corev1.Protocol, port int32) string {
+	return fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), port)
+}
+
+// reconcileProxiedServices applies the ConfigMaps of the TCP and UDP services and removes the unused ones.
+func (r *NginxIngressControllerReconciler) reconcileProxiedServices(ctx context.Context, log logr.Logger, instance *v1beta1.NginxIngressController) error {
+	for _, protocol := range []corev1.Protocol{corev1.ProtocolTCP, corev1.ProtocolUDP} {
+		if len(proxiedServices(instance, protocol)) == 0 {
+			key := types.NamespacedName{Name: proxiedServicesName(instance, protocol), Namespace: instance.Namespace}
+			if err := r.deleteIfOwned(ctx, log, key, &corev1.ConfigMap{}, instance); err != nil {
+				return err
+			}
+			continue
+		}
+		cm, err := proxiedServicesConfigMapForNginxIngressController(instance, protocol, r.Scheme)
+		if err != nil {
+			return err
+		}
+		if err := r.apply(ctx, cm); err != nil {
+			log.Error(err, "Failed to apply ConfigMap", "ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
+			return err
+		}
+	}
+	return nil
+}
+
+// proxiedServicesConfigMapForNginxIngressController returns the ConfigMap of the TCP or UDP services, which maps
+// each port of the Ingress Controller to <namespace>/<service>:<port>.
+func proxiedServicesConfigMapForNginxIngressController(instance *v1beta1.NginxIngressController, protocol corev1.Protocol, scheme *runtime.Scheme) (*corev1.ConfigMap, error) {
+	data := map[string]string{}
+	for _, service := range proxiedServices(instance, protocol) {
+		namespace := service.Namespace
+		if namespace == "" {
+			namespace = instance.Namespace
+		}
+		data[strconv.Itoa(int(service.Port))] = fmt.Sprintf("%s/%s:%s", namespace, service.ServiceName, service.ServicePort.String())
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      proxiedServicesName(instance, protocol),
+			Namespace: instance.Namespace,
+		},
+		Data: data,
+	}
+	if err := ctrl.SetControllerReference(instance, cm, scheme); err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+// proxiedContainerPorts returns the container ports of the TCP and UDP services.
+func proxiedContainerPorts(instance *v1beta1.NginxIngressController) []corev1.ContainerPort {
+	var ports []corev1.ContainerPort
+	for _, protocol := range []corev1.Protocol{corev1.ProtocolTCP, corev1.ProtocolUDP} {
+		for _, service := range proxiedServices(instance, protocol) {
+			ports = append(ports, corev1.ContainerPort{
+				Name:          proxiedPortName(protocol, service.Port),
+				ContainerPort: service.Port,
+				Protocol:      protocol,
+			})
+		}
+	}
+	return ports
+}
+
+// proxiedServicePorts returns the Service ports of the TCP and UDP services.
+func proxiedServicePorts(instance *v1beta1.NginxIngressController) []corev1.ServicePort {
+	var ports []corev1.ServicePort
+	for _, protocol := range []corev1.Protocol{corev1.ProtocolTCP, corev1.ProtocolUDP} {
+		for _, service := range proxiedServices(instance, protocol) {
+			ports = append(ports, corev1.ServicePort{
+				Name:       proxiedPortName(protocol, service.Port),
+				Port:       service.Port,
+				Protocol:   protocol,
+				TargetPort: intstr.FromString(proxiedPortName(protocol, service.Port)),
+			})
+		}
+	}
+	return ports
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+func TestProxiedServices(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	instance := newTestInstance()
+	instance.Spec.TCPServices = []v1beta1.ProxiedService{
+		{Port: 5432, Namespace: "db", ServiceName: "postgres", ServicePort: intstr.FromInt(5432)},
+	}
+	instance.Spec.UDPServices = []v1beta1.ProxiedService{
+		{Port: 53, ServiceName: "dns", ServicePort: intstr.FromString("dns")},
+	}
+
+	tcp, err := proxiedServicesConfigMapForNginxIngressController(instance, corev1.ProtocolTCP, scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"5432": "db/postgres:5432"}; tcp.Name != "nginx-tcp-services" || !reflect.DeepEqual(tcp.Data, expected) {
+		t.Errorf("proxiedServicesConfigMapForNginxIngressController(TCP) returned %s with %v but expected nginx-tcp-services with %v", tcp.Name, tcp.Data, expected)
+	}
+	udp, err := proxiedServicesConfigMapForNginxIngressController(instance, corev1.ProtocolUDP, scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"53": "default/dns:dns"}; !reflect.DeepEqual(udp.Data, expected) {
+		t.Errorf("proxiedServicesConfigMapForNginxIngressController(UDP) returned %v but expected %v", udp.Data, expected)
+	}
+
+	args := generatePodArgs(instance)
+	for _, arg := range []string{"--tcp-services-configmap=$(POD_NAMESPACE)/nginx-tcp-services", "--udp-services-configmap=$(POD_NAMESPACE)/nginx-udp-services"} {
+		if !containsStr(args, arg) {
+			t.Errorf("generatePodArgs() returned %v but expected %s", args, arg)
+		}
+	}
+
+	svc, err := serviceForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, port := range svc.Spec.Ports {
+		names = append(names, port.Name)
+	}
+	if expected := []string{"http", "https", "tcp-5432", "udp-53"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("serviceForNginxIngressController() returned ports %v but expected %v", names, expected)
+	}
+}
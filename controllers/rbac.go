@@ -17,8 +17,11 @@ limitations under the License.
 package controllers
 
 import (
+	"fmt"
+
 	rbacv1 "k8s.io/api/rbac/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
 )
 
 func clusterRoleForNginxIngressController(name string) *rbacv1.ClusterRole {
@@ -28,7 +31,7 @@ func clusterRoleForNginxIngressController(name string) *rbacv1.ClusterRole {
 			{
 				Resources: []string{"namespaces"},
 				APIGroups: []string{""},
-				Verbs:     []string{"get"},
+				Verbs:     []string{"get", "list", "watch"},
 			},
 			{
 				Resources: []string{"configmaps", "pods", "secrets", "endpoints", "services"},
@@ -84,3 +87,139 @@ func clusterRoleBindingForNginxIngressController(name string) *rbacv1.ClusterRol
 		},
 	}
 }
+
+// clusterRoleForIngressClasses returns the only cluster-scoped permissions of an Ingress Controller that watches a
+// single namespace.
+func clusterRoleForIngressClasses(name string) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: v1.ObjectMeta{Name: name},
+		Rules: []rbacv1.PolicyRule{
+			{
+				Resources: []string{"ingressclasses"},
+				APIGroups: []string{"networking.k8s.io"},
+				Verbs:     []string{"get", "list", "watch"},
+			},
+		},
+	}
+}
+
+// watchNamespaceRules are the permissions of the Ingress Controller in the namespace it watches.
+func watchNamespaceRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			Resources: []string{"configmaps", "pods", "secrets", "endpoints", "services"},
+			APIGroups: []string{""},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			Resources: []string{"ingresses"},
+			APIGroups: []string{"networking.k8s.io"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			Resources: []string{"ingresses/status"},
+			APIGroups: []string{"networking.k8s.io"},
+			Verbs:     []string{"update"},
+		},
+		{
+			Resources: []string{"events"},
+			APIGroups: []string{""},
+			Verbs:     []string{"create", "patch"},
+		},
+	}
+}
+
+// instanceNamespaceRules are the permissions of the Ingress Controller in its own namespace: its configuration,
+// its leader election and the status of its pods and Service.
+func instanceNamespaceRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			Resources: []string{"configmaps", "pods", "secrets", "endpoints", "services"},
+			APIGroups: []string{""},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			Resources: []string{"configmaps"},
+			APIGroups: []string{""},
+			Verbs:     []string{"get", "update", "create"},
+		},
+		{
+			Resources: []string{"leases"},
+			APIGroups: []string{"coordination.k8s.io"},
+			Verbs:     []string{"get", "update", "create"},
+		},
+		{
+			Resources: []string{"events"},
+			APIGroups: []string{""},
+			Verbs:     []string{"create", "patch"},
+		},
+	}
+}
+
+// namespacedRBACName returns the name of the per-instance Roles, RoleBindings and ClusterRoleBinding.
+// It includes the namespace of the instance because the objects may live in another namespace or be cluster-scoped.
+func namespacedRBACName(instance *v1beta1.NginxIngressController) string {
+	return fmt.Sprintf("ingress-nginx-%s-%s", instance.Namespace, instance.Name)
+}
+
+// rolesForNginxIngressController returns the Roles of an Ingress Controller that watches a single namespace: one in
+// its own namespace and, if different, one in the watched namespace. They are labelled with the instance instead of
+// carrying an owner reference, which cannot cross namespaces.
+func rolesForNginxIngressController(instance *v1beta1.NginxIngressController) []*rbacv1.Role {
+	own := &rbacv1.Role{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      namespacedRBACName(instance),
+			Namespace: instance.Namespace,
+			Labels:    instanceLabels(instance),
+		},
+		Rules: instanceNamespaceRules(),
+	}
+	watchNamespace := singleWatchNamespace(instance)
+	if watchNamespace == instance.Namespace {
+		own.Rules = append(own.Rules, watchNamespaceRules()...)
+		return []*rbacv1.Role{own}
+	}
+
+	watched := &rbacv1.Role{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      namespacedRBACName(instance),
+			Namespace: watchNamespace,
+			Labels:    instanceLabels(instance),
+		},
+		Rules: watchNamespaceRules(),
+	}
+	return []*rbacv1.Role{own, watched}
+}
+
+func roleBindingForRole(instance *v1beta1.NginxIngressController, role *rbacv1.Role) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      role.Name,
+			Namespace: role.Namespace,
+			Labels:    instanceLabels(instance),
+		},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "Role",
+			Name:     role.Name,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+		Subjects: []rbacv1.Subject{subjectForServiceAccount(instance.Namespace, instance.Name)},
+	}
+}
+
+// clusterRoleBindingForIngressClasses binds the ServiceAccount of an Ingress Controller that watches a single
+// namespace to the ClusterRole of the IngressClasses.
+func clusterRoleBindingForIngressClasses(instance *v1beta1.NginxIngressController) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   namespacedRBACName(instance),
+			Labels: instanceLabels(instance),
+		},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "ClusterRole",
+			Name:     ingressClassClusterRoleName,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+		Subjects: []rbacv1.Subject{subjectForServiceAccount(instance.Namespace, instance.Name)},
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+)
+
+func TestRolesForNginxIngressController(t *testing.T) {
+	tests := []struct {
+		watchNamespace string
+		namespaces     []string
+		rules          []int
+	}{
+		{watchNamespace: "apps", namespaces: []string{"default", "apps"}, rules: []int{4, 4}},
+		{watchNamespace: "default", namespaces: []string{"default"}, rules: []int{8}},
+	}
+	for _, test := range tests {
+		instance := newTestInstance()
+		instance.Spec.WatchNamespace = test.watchNamespace
+
+		roles := rolesForNginxIngressController(instance)
+		if len(roles) != len(test.namespaces) {
+			t.Fatalf("rolesForNginxIngressController(%q) returned %d Roles but expected %d", test.watchNamespace, len(roles), len(test.namespaces))
+		}
+		for i, role := range roles {
+			if role.Namespace != test.namespaces[i] || len(role.Rules) != test.rules[i] {
+				t.Errorf("rolesForNginxIngressController(%q) returned Role %s/%s with %d rules but expected namespace %s with %d rules",
+					test.watchNamespace, role.Namespace, role.Name, len(role.Rules), test.namespaces[i], test.rules[i])
+			}
+			if role.Labels[instanceNameLabel] != instance.Name || role.Labels[instanceNamespaceLabel] != instance.Namespace {
+				t.Errorf("rolesForNginxIngressController(%q) returned Role %s/%s without the instance labels", test.watchNamespace, role.Namespace, role.Name)
+			}
+			rb := roleBindingForRole(instance, role)
+			if rb.Namespace != role.Namespace || rb.RoleRef.Name != role.Name {
+				t.Errorf("roleBindingForRole() returned RoleBinding %s/%s bound to %s", rb.Namespace, rb.Name, rb.RoleRef.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+const (
+	// nginxConfigInitContainer copies the nginx configuration of the image to a writable volume, as ingress-nginx
+	// writes nginx.conf in /etc/nginx.
+	nginxConfigInitContainer = "copy-nginx-config"
+	nginxConfigCopyPath      = "/nginx-config"
+)
+
+// restrictedSecurity returns true if the pods of nginx comply with the restricted Pod Security Standard.
+func restrictedSecurity(instance *v1beta1.NginxIngressController) bool {
+	return instance.Spec.Workload != nil && instance.Spec.Workload.SecurityPreset == v1beta1.SecurityPresetRestricted
+}
+
+// httpPort returns the HTTP port nginx listens on.
+func httpPort(instance *v1beta1.NginxIngressController) int32 {
+	if restrictedSecurity(instance) {
+		return v1beta1.RestrictedHTTPPort
+	}
+	return v1beta1.HTTPPort
+}
+
+// httpsPort returns the HTTPS port nginx listens on.
+func httpsPort(instance *v1beta1.NginxIngressController) int32 {
+	if restrictedSecurity(instance) {
+		return v1beta1.RestrictedHTTPSPort
+	}
+	return v1beta1.HTTPSPort
+}
+
+// containerSecurityContext returns the security context of the nginx container: the one of the workload or the one
+// of the security preset.
+func containerSecurityContext(instance *v1beta1.NginxIngressController) *corev1.SecurityContext {
+	if sc := instance.Spec.Workload.SecurityContext; sc != nil {
+		return sc
+	}
+	runAsUser := new(int64)
+	*runAsUser = 101
+	if restrictedSecurity(instance) {
+		return restrictedSecurityContext(runAsUser)
+	}
+	allowPrivilegeEscalation := new(bool)
+	*allowPrivilegeEscalation = true
+	return &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+			Add:  []corev1.Capability{"NET_BIND_SERVICE"},
+		},
+		RunAsUser:                runAsUser,
+		AllowPrivilegeEscalation: allowPrivilegeEscalation,
+	}
+}
+
+// restrictedSecurityContext returns a container security context that complies with the restricted Pod Security
+// Standard. Without privilege escalation, nginx does not get the file capability that binds the privileged ports, but
+// the capability must stay in the bounding set: the kernel refuses with EPERM to execute a binary with a file
+// capability missing from the bounding set.
+func restrictedSecurityContext(runAsUser *int64) *corev1.SecurityContext {
+	runAsNonRoot := new(bool)
+	readOnlyRootFilesystem := new(bool)
+	allowPrivilegeEscalation := new(bool)
+	*runAsNonRoot = true
+	*readOnlyRootFilesystem = true
+	return &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+			Add:  []corev1.Capability{"NET_BIND_SERVICE"},
+		},
+		RunAsUser:                runAsUser,
+		RunAsNonRoot:             runAsNonRoot,
+		ReadOnlyRootFilesystem:   readOnlyRootFilesystem,
+		AllowPrivilegeEscalation: allowPrivilegeEscalation,
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+// podSecurityContext returns the security context of the pods of nginx: the one of the workload or the one of the
+// security preset.
+func podSecurityContext(instance *v1beta1.NginxIngressController) *corev1.PodSecurityContext {
+	if sc := instance.Spec.Workload.PodSecurityContext; sc != nil {
+		return sc
+	}
+	if !restrictedSecurity(instance) {
+		return nil
+	}
+	runAsNonRoot := new(bool)
+	*runAsNonRoot = true
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot:   runAsNonRoot,
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+// addWritableVolumes adds the volumes that nginx writes to with a read-only root filesystem: a copy of /etc/nginx
+// made by an init container, /tmp and /etc/ingress-controller.
+func addWritableVolumes(instance *v1beta1.NginxIngressController, template *corev1.PodTemplateSpec) {
+	if !restrictedSecurity(instance) {
+		return
+	}
+
+	container := &template.Spec.Containers[0]
+	initContainer := corev1.Container{
+		Name:            nginxConfigInitContainer,
+		Image:           container.Image,
+		ImagePullPolicy: container.ImagePullPolicy,
+		Command:         []string{"cp", "-a", "/etc/nginx/.", nginxConfigCopyPath},
+		SecurityContext: container.SecurityContext,
+		VolumeMounts:    []corev1.VolumeMount{{Name: "nginx-config", MountPath: nginxConfigCopyPath}},
+	}
+	template.Spec.InitContainers = append(template.Spec.InitContainers, initContainer)
+
+	for _, mount := range []corev1.VolumeMount{
+		{Name: "nginx-config", MountPath: "/etc/nginx"},
+		{Name: "tmp", MountPath: "/tmp"},
+		{Name: "ingress-controller", MountPath: "/etc/ingress-controller"},
+	} {
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+		template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+			Name:         mount.Name,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+}
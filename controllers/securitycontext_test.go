@@ -0,0 +1,159 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+// restrictedPodStartErrors returns why the pods rendered with the Restricted preset would be rejected by the
+// restricted Pod Security Standard or would not start: the nginx binary has the NET_BIND_SERVICE file capability,
+// which must stay in the bounding set for execve to succeed, and without privilege escalation nginx can only bind the
+// unprivileged ports.
+func restrictedPodStartErrors(spec corev1.PodSpec) []string {
+	var errs []string
+	runAsNonRoot := spec.SecurityContext != nil && spec.SecurityContext.RunAsNonRoot != nil && *spec.SecurityContext.RunAsNonRoot
+	seccomp := spec.SecurityContext != nil && spec.SecurityContext.SeccompProfile != nil
+	for _, container := range append(spec.InitContainers, spec.Containers...) {
+		sc := container.SecurityContext
+		if sc == nil || sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			errs = append(errs, container.Name+" allows privilege escalation")
+			continue
+		}
+		if !runAsNonRoot && (sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot) {
+			errs = append(errs, container.Name+" may run as root")
+		}
+		if !seccomp && sc.SeccompProfile == nil {
+			errs = append(errs, container.Name+" has no seccomp profile")
+		}
+		if sc.Capabilities == nil || len(sc.Capabilities.Drop) != 1 || sc.Capabilities.Drop[0] != "ALL" {
+			errs = append(errs, container.Name+" does not drop all the capabilities")
+			continue
+		}
+		if len(sc.Capabilities.Add) != 1 || sc.Capabilities.Add[0] != "NET_BIND_SERVICE" {
+			errs = append(errs, fmt.Sprintf("%s adds capabilities %v, the nginx binary cannot be executed without NET_BIND_SERVICE", container.Name, sc.Capabilities.Add))
+		}
+		seen := map[int32]bool{}
+		for _, port := range container.Ports {
+			if port.ContainerPort < 1024 {
+				errs = append(errs, fmt.Sprintf("%s cannot bind the privileged port %d", container.Name, port.ContainerPort))
+			}
+			if seen[port.ContainerPort] {
+				errs = append(errs, fmt.Sprintf("%s binds port %d twice", container.Name, port.ContainerPort))
+			}
+			seen[port.ContainerPort] = true
+		}
+	}
+	return errs
+}
+
+func TestRestrictedSecurityPreset(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	instance := newTestInstance()
+	instance.Spec.Workload.SecurityPreset = v1beta1.SecurityPresetRestricted
+	instance.Spec.AdmissionWebhook = &v1beta1.AdmissionWebhook{Enabled: true}
+	instance.Spec.TCPServices = []v1beta1.ProxiedService{{Port: 5432, ServiceName: "postgres", ServicePort: intstr.FromInt(5432)}}
+	if err := instance.ValidateSpec(); err != nil {
+		t.Fatalf("ValidateSpec() returned error %v", err)
+	}
+
+	spec := podTemplateForNginxIngressController(instance).Spec
+	if errs := restrictedPodStartErrors(spec); len(errs) > 0 {
+		t.Errorf("podTemplateForNginxIngressController() rendered a pod that cannot start: %v", errs)
+	}
+	if sc := spec.Containers[0].SecurityContext; sc.ReadOnlyRootFilesystem == nil || !*sc.ReadOnlyRootFilesystem {
+		t.Errorf("podTemplateForNginxIngressController() rendered security context %+v", sc)
+	}
+	if len(spec.InitContainers) != 1 || spec.InitContainers[0].Name != nginxConfigInitContainer {
+		t.Errorf("podTemplateForNginxIngressController() rendered init containers %v", spec.InitContainers)
+	}
+	ports := map[string]int32{}
+	for _, port := range spec.Containers[0].Ports {
+		ports[port.Name] = port.ContainerPort
+	}
+	if ports["http"] != v1beta1.RestrictedHTTPPort || ports["https"] != v1beta1.RestrictedHTTPSPort {
+		t.Errorf("podTemplateForNginxIngressController() rendered ports %v", spec.Containers[0].Ports)
+	}
+	args := generatePodArgs(instance)
+	if !containsStr(args, "--http-port=8080") || !containsStr(args, "--https-port=10443") {
+		t.Errorf("generatePodArgs() returned %v without the unprivileged ports", args)
+	}
+
+	svc, err := serviceForNginxIngressController(instance, scheme)
+	if err != nil {
+		t.Fatalf("serviceForNginxIngressController() returned error %v", err)
+	}
+	for _, port := range svc.Spec.Ports[:2] {
+		if want := intstr.FromInt(int(ports[port.Name])); port.TargetPort != want {
+			t.Errorf("serviceForNginxIngressController() set target port %v of port %s but expected %v", port.TargetPort, port.Name, want)
+		}
+	}
+
+	webhookPort := int32(v1beta1.RestrictedHTTPSPort)
+	metricsPort := uint16(v1beta1.RestrictedHTTPPort)
+	invalid := []func(*v1beta1.NginxIngressController){
+		func(instance *v1beta1.NginxIngressController) { instance.Spec.Workload.HostNetwork = true },
+		func(instance *v1beta1.NginxIngressController) { instance.Spec.AdmissionWebhook.Port = &webhookPort },
+		func(instance *v1beta1.NginxIngressController) {
+			instance.Spec.Metrics = &v1beta1.Metrics{Port: &metricsPort}
+		},
+		func(instance *v1beta1.NginxIngressController) {
+			instance.Spec.TCPServices[0].Port = v1beta1.RestrictedHTTPPort
+		},
+		func(instance *v1beta1.NginxIngressController) { instance.Spec.TCPServices[0].Port = 443 },
+		func(instance *v1beta1.NginxIngressController) {
+			instance.Spec.UDPServices = []v1beta1.ProxiedService{{Port: 53, ServiceName: "dns", ServicePort: intstr.FromInt(53)}}
+		},
+	}
+	for i, mutate := range invalid {
+		instance := instance.DeepCopy()
+		mutate(instance)
+		if err := instance.ValidateSpec(); err == nil {
+			t.Errorf("ValidateSpec() accepted invalid spec %d with the Restricted security preset", i)
+		}
+	}
+}
+
+func TestSecurityContextOverrides(t *testing.T) {
+	instance := newTestInstance()
+	if sc := containerSecurityContext(instance); len(sc.Capabilities.Add) != 1 || sc.Capabilities.Add[0] != "NET_BIND_SERVICE" {
+		t.Errorf("containerSecurityContext() returned %+v but expected the Default preset", sc)
+	}
+	if sc := podSecurityContext(instance); sc != nil {
+		t.Errorf("podSecurityContext() returned %+v but expected none", sc)
+	}
+
+	runAsUser := int64(1000)
+	instance.Spec.Workload.SecurityPreset = v1beta1.SecurityPresetRestricted
+	instance.Spec.Workload.SecurityContext = &corev1.SecurityContext{RunAsUser: &runAsUser}
+	instance.Spec.Workload.PodSecurityContext = &corev1.PodSecurityContext{FSGroup: &runAsUser}
+	if sc := containerSecurityContext(instance); sc != instance.Spec.Workload.SecurityContext {
+		t.Errorf("containerSecurityContext() returned %+v but expected the override", sc)
+	}
+	if sc := podSecurityContext(instance); sc != instance.Spec.Workload.PodSecurityContext {
+		t.Errorf("podSecurityContext() returned %+v but expected the override", sc)
+	}
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+// workloadStatus is the status of the workload of the Ingress Controller, independent of its kind.
+type workloadStatus struct {
+	exists          bool
+	upToDate        bool
+	replicas        int32
+	readyReplicas   int32
+	updatedReplicas int32
+	image           string
+	// progressDeadlineExceeded is true if the new pods of a Deployment did not become ready in time.
+	progressDeadlineExceeded bool
+	// stalledReplicaSet is the ReplicaSet of the new pods of a Deployment that did not become ready in time, and
+	// stalledImage its image.
+	stalledReplicaSet string
+	stalledImage      string
+}
+
+// stalledReplicaSetPattern matches the message of the ProgressDeadlineExceeded condition of a Deployment.
+var stalledReplicaSetPattern = regexp.MustCompile(`^ReplicaSet "([^"]+)" has timed out progressing`)
+
+func workloadStatusFromDeployment(dep *appsv1.Deployment) workloadStatus {
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	progressing := deploymentCondition(dep, appsv1.DeploymentProgressing)
+	status := workloadStatus{
+		exists:          true,
+		upToDate:        dep.Status.ObservedGeneration >= dep.Generation,
+		replicas:        replicas,
+		readyReplicas:   dep.Status.ReadyReplicas,
+		updatedReplicas: dep.Status.UpdatedReplicas,
+		image:           dep.Spec.Template.Spec.Containers[0].Image,
+		progressDeadlineExceeded: progressing != nil && progressing.Status == corev1.ConditionFalse &&
+			progressing.Reason == "ProgressDeadlineExceeded",
+	}
+	if status.progressDeadlineExceeded {
+		if match := stalledReplicaSetPattern.FindStringSubmatch(progressing.Message); match != nil {
+			status.stalledReplicaSet = match[1]
+		}
+	}
+	return status
+}
+
+func deploymentCondition(dep *appsv1.Deployment, conditionType appsv1.DeploymentConditionType) *appsv1.DeploymentCondition {
+	for i := range dep.Status.Conditions {
+		if dep.Status.Conditions[i].Type == conditionType {
+			return &dep.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func workloadStatusFromDaemonSet(ds *appsv1.DaemonSet) workloadStatus {
+	return workloadStatus{
+		exists:          true,
+		upToDate:        ds.Status.ObservedGeneration >= ds.Generation,
+		replicas:        ds.Status.DesiredNumberScheduled,
+		readyReplicas:   ds.Status.NumberReady,
+		updatedReplicas: ds.Status.UpdatedNumberScheduled,
+		image:           ds.Spec.Template.Spec.Containers[0].Image,
+	}
+}
+
+// getWorkloadStatus returns the status of the workload of the kind set in the spec.
+func (r *NginxIngressControllerReconciler) getWorkloadStatus(ctx context.Context, instance *v1beta1.NginxIngressController) (workloadStatus, error) {
+	key := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+	if instance.Spec.Workload != nil && instance.Spec.Workload.Kind == v1beta1.WorkloadKindDaemonSet {
+		ds := &appsv1.DaemonSet{}
+		if err := r.Get(ctx, key, ds); err != nil {
+			if errors.IsNotFound(err) {
+				return workloadStatus{}, nil
+			}
+			return workloadStatus{}, err
+		}
+		return workloadStatusFromDaemonSet(ds), nil
+	}
+	dep := &appsv1.Deployment{}
+	if err := r.Get(ctx, key, dep); err != nil {
+		if errors.IsNotFound(err) {
+			return workloadStatus{}, nil
+		}
+		return workloadStatus{}, err
+	}
+	status := workloadStatusFromDeployment(dep)
+	if status.stalledReplicaSet != "" {
+		// ReplicaSets are not cached, they are only read when a rollout did not progress in time.
+		rs := &appsv1.ReplicaSet{}
+		err := r.APIReader.Get(ctx, types.NamespacedName{Name: status.stalledReplicaSet, Namespace: dep.Namespace}, rs)
+		if err != nil && !errors.IsNotFound(err) {
+			return workloadStatus{}, err
+		}
+		if err == nil && metav1.IsControlledBy(rs, dep) && len(rs.Spec.Template.Spec.Containers) > 0 {
+			status.stalledImage = rs.Spec.Template.Spec.Containers[0].Image
+		}
+	}
+	return status, nil
+}
+
+// updateStatus recomputes the status of the NginxIngressController from the observed objects.
+// configErr is the validation error of the spec, reconcileErr the error of the reconciliation.
+func (r *NginxIngressControllerReconciler) updateStatus(ctx context.Context, instance *v1beta1.NginxIngressController, configErr, reconcileErr error) error {
+	status := instance.Status.DeepCopy()
+	status.ObservedGeneration = instance.Generation
+
+	if configErr != nil {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionConfigValid,
+			Status:             metav1.ConditionFalse,
+			Reason:             "InvalidSpec",
+			Message:            configErr.Error(),
+			ObservedGeneration: instance.Generation,
+		})
+	} else if unknown := v1beta1.UnknownConfigMapKeys(instance.Spec.ConfigMapData); len(unknown) > 0 {
+		// The unknown options may be valid for the version of ingress-nginx, so they do not block the reconciliation.
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionConfigValid,
+			Status:             metav1.ConditionTrue,
+			Reason:             "UnknownConfigMapOptions",
+			Message:            fmt.Sprintf("The spec is valid, configMapData sets options unknown to the Operator that are passed as is: %s", strings.Join(unknown, ", ")),
+			ObservedGeneration: instance.Generation,
+		})
+	} else {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionConfigValid,
+			Status:             metav1.ConditionTrue,
+			Reason:             "ValidSpec",
+			Message:            "The spec is valid",
+			ObservedGeneration: instance.Generation,
+		})
+	}
+
+	if configErr == nil {
+		if err := checkImageVersion(instance); err != nil {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               v1beta1.ConditionImageSupported,
+				Status:             metav1.ConditionFalse,
+				Reason:             "UnsupportedKubernetesVersion",
+				Message:            fmt.Sprintf("The running images are kept: %v", err),
+				ObservedGeneration: instance.Generation,
+			})
+		} else {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               v1beta1.ConditionImageSupported,
+				Status:             metav1.ConditionTrue,
+				Reason:             "SupportedKubernetesVersion",
+				Message:            "The images support the version of Kubernetes",
+				ObservedGeneration: instance.Generation,
+			})
+		}
+	}
+
+	if instance.IsDefaultIngressClass() {
+		owner, err := r.defaultIngressClassOwner(ctx, instance)
+		if err != nil {
+			return err
+		}
+		if owner != nil {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               v1beta1.ConditionDefaultIngressClass,
+				Status:             metav1.ConditionFalse,
+				Reason:             "DefaultClassConflict",
+				Message:            fmt.Sprintf("The default IngressClass is already %s of %s/%s", owner.Spec.IngressClass, owner.Namespace, owner.Name),
+				ObservedGeneration: instance.Generation,
+			})
+		} else {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               v1beta1.ConditionDefaultIngressClass,
+				Status:             metav1.ConditionTrue,
+				Reason:             "DefaultClass",
+				Message:            fmt.Sprintf("The IngressClass %s is the default", instance.Spec.IngressClass),
+				ObservedGeneration: instance.Generation,
+			})
+		}
+	} else {
+		meta.RemoveStatusCondition(&status.Conditions, v1beta1.ConditionDefaultIngressClass)
+	}
+
+	workload, err := r.getWorkloadStatus(ctx, instance)
+	if err != nil {
+		return err
+	}
+	status.Replicas = workload.replicas
+	status.ReadyReplicas = workload.readyReplicas
+	status.Image = workload.image
+
+	status.Canary, err = r.canaryStatus(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	svc := &corev1.Service{}
+	err = r.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, svc)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	status.LoadBalancer = svc.Status.LoadBalancer.Ingress
+
+	available := workload.exists && workload.readyReplicas > 0
+	if available {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionAvailable,
+			Status:             metav1.ConditionTrue,
+			Reason:             "MinimumReplicasAvailable",
+			Message:            fmt.Sprintf("%d/%d pods are ready", workload.readyReplicas, workload.replicas),
+			ObservedGeneration: instance.Generation,
+		})
+	} else {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionAvailable,
+			Status:             metav1.ConditionFalse,
+			Reason:             "NoReplicasAvailable",
+			Message:            fmt.Sprintf("%d/%d pods are ready", workload.readyReplicas, workload.replicas),
+			ObservedGeneration: instance.Generation,
+		})
+	}
+
+	progressing := workload.exists && (!workload.upToDate ||
+		workload.updatedReplicas < workload.replicas || workload.readyReplicas < workload.replicas)
+	status.Upgrade = upgradeStatus(instance, workload, !progressing)
+
+	if progressing {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionProgressing,
+			Status:             metav1.ConditionTrue,
+			Reason:             "RolloutInProgress",
+			Message:            fmt.Sprintf("%d/%d pods are updated", workload.updatedReplicas, workload.replicas),
+			ObservedGeneration: instance.Generation,
+		})
+	} else {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionProgressing,
+			Status:             metav1.ConditionFalse,
+			Reason:             "RolloutComplete",
+			Message:            "The workload is up to date",
+			ObservedGeneration: instance.Generation,
+		})
+	}
+
+	switch {
+	case configErr != nil:
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             "InvalidSpec",
+			Message:            configErr.Error(),
+			ObservedGeneration: instance.Generation,
+		})
+	case reconcileErr != nil:
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             "ReconcileFailed",
+			Message:            reconcileErr.Error(),
+			ObservedGeneration: instance.Generation,
+		})
+	case status.Upgrade != nil && status.Upgrade.FailedImage != "":
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             "UpgradeFailed",
+			Message:            status.Upgrade.Message,
+			ObservedGeneration: instance.Generation,
+		})
+	case !available && !progressing:
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             "NoReplicasAvailable",
+			Message:            "No Ingress Controller pod is ready",
+			ObservedGeneration: instance.Generation,
+		})
+	default:
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionDegraded,
+			Status:             metav1.ConditionFalse,
+			Reason:             "AsExpected",
+			Message:            "The Ingress Controller is reconciled",
+			ObservedGeneration: instance.Generation,
+		})
+	}
+
+	status.Deployed = available
+
+	if reflect.DeepEqual(status, &instance.Status) {
+		return nil
+	}
+	instance.Status = *status
+	return r.Status().Update(ctx, instance)
+}
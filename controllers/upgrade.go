@@ -0,0 +1,158 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+// checkImageVersion returns an error if the image of the Ingress Controller or of its canary does not support the
+// version of the cluster.
+func checkImageVersion(instance *v1beta1.NginxIngressController) error {
+	if err := checkTagVersion(instance, imageTag(instance)); err != nil {
+		return err
+	}
+	if instance.Spec.Canary != nil {
+		return checkTagVersion(instance, instance.Spec.Canary.Image.Tag)
+	}
+	return nil
+}
+
+// checkTagVersion returns an error if the ingress-nginx version of the tag does not support the version of the
+// cluster, unless the check is skipped.
+func checkTagVersion(instance *v1beta1.NginxIngressController, tag string) error {
+	if instance.Spec.Upgrade != nil && instance.Spec.Upgrade.SkipVersionCheck {
+		return nil
+	}
+	return v1beta1.CheckImageTag(tag, RunningK8sVersion)
+}
+
+// imageTag returns the tag of the image of the spec, or the default tag of the Operator.
+func imageTag(instance *v1beta1.NginxIngressController) string {
+	if instance.Spec.Image.Tag != "" {
+		return instance.Spec.Image.Tag
+	}
+	return v1beta1.DefaultImageTag(RunningK8sVersion)
+}
+
+// specImage returns the image of the spec, with the default tag of the Operator if it is not set.
+func specImage(instance *v1beta1.NginxIngressController) string {
+	return generateImage(instance.Spec.Image.Repository, imageTag(instance))
+}
+
+// workloadImage returns the image to deploy: the image of the spec, the last successful image when the upgrade to
+// the image of the spec failed and was rolled back, or the running image when the image of the spec does not
+// support the version of the cluster.
+func workloadImage(instance *v1beta1.NginxIngressController) string {
+	image := specImage(instance)
+	if upgradeFailed(instance, v1beta1.UpgradePhaseRolledBack) && instance.Status.Upgrade.LastSuccessfulImage != "" {
+		return instance.Status.Upgrade.LastSuccessfulImage
+	}
+	if checkTagVersion(instance, imageTag(instance)) != nil && instance.Status.Image != "" {
+		return instance.Status.Image
+	}
+	return image
+}
+
+// upgradeFailed returns true if the upgrade to the image of the spec failed and ended in one of the phases.
+func upgradeFailed(instance *v1beta1.NginxIngressController, phases ...string) bool {
+	up := instance.Status.Upgrade
+	if up == nil || up.FailedImage != specImage(instance) {
+		return false
+	}
+	if len(phases) == 0 {
+		return up.Phase == v1beta1.UpgradePhasePaused || up.Phase == v1beta1.UpgradePhaseRolledBack
+	}
+	return containsStr(phases, up.Phase)
+}
+
+// setDeploymentStrategy sets the rollout strategy of the Deployment and pauses it after a failed upgrade.
+func setDeploymentStrategy(dep *appsv1.Deployment, instance *v1beta1.NginxIngressController) {
+	if up := instance.Spec.Upgrade; up != nil {
+		dep.Spec.MinReadySeconds = up.MinReadySeconds
+		dep.Spec.ProgressDeadlineSeconds = up.ProgressDeadlineSeconds
+		if up.MaxSurge != nil || up.MaxUnavailable != nil {
+			dep.Spec.Strategy = appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: up.MaxSurge, MaxUnavailable: up.MaxUnavailable},
+			}
+		}
+	}
+	dep.Spec.Paused = upgradeFailed(instance, v1beta1.UpgradePhasePaused)
+}
+
+// setDaemonSetStrategy sets the rollout strategy of the DaemonSet.
+func setDaemonSetStrategy(ds *appsv1.DaemonSet, instance *v1beta1.NginxIngressController) {
+	if up := instance.Spec.Upgrade; up != nil {
+		ds.Spec.MinReadySeconds = up.MinReadySeconds
+		if up.MaxSurge != nil || up.MaxUnavailable != nil {
+			ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxSurge: up.MaxSurge, MaxUnavailable: up.MaxUnavailable},
+			}
+		}
+	}
+}
+
+// upgradeStatus computes the progress of the upgrade to the image of the spec from the observed workload.
+// A failed upgrade keeps its phase until the image of the spec changes.
+func upgradeStatus(instance *v1beta1.NginxIngressController, workload workloadStatus, rolledOut bool) *v1beta1.UpgradeStatus {
+	target := specImage(instance)
+	status := &v1beta1.UpgradeStatus{}
+	if instance.Status.Upgrade != nil {
+		status = instance.Status.Upgrade.DeepCopy()
+	}
+	if !workload.exists {
+		return status
+	}
+	if upgradeFailed(instance) {
+		status.UpdatedReplicas = 0
+		return status
+	}
+
+	status.TargetImage = target
+	status.FailedImage = ""
+	status.UpdatedReplicas = 0
+	if workload.image == target {
+		status.UpdatedReplicas = workload.updatedReplicas
+	}
+
+	switch {
+	case workload.image == target && rolledOut:
+		status.Phase = v1beta1.UpgradePhaseComplete
+		status.LastSuccessfulImage = target
+		status.Message = fmt.Sprintf("%s is rolled out", target)
+	// A ProgressDeadlineExceeded condition left from an earlier rollout does not fail a newly applied image.
+	case workload.upToDate && workload.progressDeadlineExceeded && workload.stalledImage == target &&
+		status.LastSuccessfulImage != target:
+		status.FailedImage = target
+		if instance.Spec.Upgrade != nil && instance.Spec.Upgrade.AutoRollback && status.LastSuccessfulImage != "" {
+			status.Phase = v1beta1.UpgradePhaseRolledBack
+			status.Message = fmt.Sprintf("the pods of %s did not become ready in time, rolled back to %s", target, status.LastSuccessfulImage)
+		} else {
+			status.Phase = v1beta1.UpgradePhasePaused
+			status.Message = fmt.Sprintf("the pods of %s did not become ready in time, the rollout is paused until the image changes", target)
+		}
+	default:
+		status.Phase = v1beta1.UpgradePhaseProgressing
+		status.Message = fmt.Sprintf("%d/%d pods run %s", status.UpdatedReplicas, workload.replicas, target)
+	}
+	return status
+}
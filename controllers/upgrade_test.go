@@ -0,0 +1,162 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
+)
+
+func TestCheckImageVersion(t *testing.T) {
+	defer func(v *version.Version) { RunningK8sVersion = v }(RunningK8sVersion)
+	RunningK8sVersion = version.MustParseGeneric("v1.24.3")
+
+	tests := []struct {
+		tag     string
+		skip    bool
+		wantErr bool
+	}{
+		{tag: "v1.3.0"},
+		{tag: "v1.3.1@sha256:54f7fe2c6c5a9db9a0ebf1131797109bb7a4d91f56b9b362bde2abd237dd1974"},
+		{tag: "v1.10.0", wantErr: true},
+		{tag: "v1.10.0", skip: true},
+		{tag: "latest"},
+		{tag: "v2.0.0"},
+	}
+	for _, test := range tests {
+		instance := newTestInstance()
+		instance.Spec.Image.Tag = test.tag
+		instance.Spec.Upgrade = &v1beta1.Upgrade{SkipVersionCheck: test.skip}
+		if err := checkImageVersion(instance); (err != nil) != test.wantErr {
+			t.Errorf("checkImageVersion(%q) returned %v but expected error %t", test.tag, err, test.wantErr)
+		}
+	}
+}
+
+func TestUpgradeStatus(t *testing.T) {
+	instance := newTestInstance()
+	instance.Spec.Upgrade = &v1beta1.Upgrade{AutoRollback: true}
+	if instance.Spec.Image.Tag != "" {
+		t.Fatalf("Default() persisted the image tag %s", instance.Spec.Image.Tag)
+	}
+	oldImage := specImage(instance)
+	if oldImage != "registry.k8s.io/ingress-nginx/controller:"+v1beta1.IngressNginxImageTag {
+		t.Fatalf("specImage() returned %s but expected the default tag", oldImage)
+	}
+
+	status := upgradeStatus(instance, workloadStatus{exists: true, image: oldImage, replicas: 1, updatedReplicas: 1}, true)
+	if status.Phase != v1beta1.UpgradePhaseComplete || status.LastSuccessfulImage != oldImage {
+		t.Fatalf("upgradeStatus() returned %v but expected %s to be complete", status, oldImage)
+	}
+	instance.Status.Upgrade = status
+
+	instance.Spec.Image.Tag = "v1.3.1"
+	newImage := generateImage(instance.Spec.Image.Repository, instance.Spec.Image.Tag)
+	// A condition left from an earlier rollout, or not yet observed by the Deployment controller, does not fail the image.
+	stale := []workloadStatus{
+		{exists: true, upToDate: true, image: newImage, replicas: 1, progressDeadlineExceeded: true, stalledImage: oldImage},
+		{exists: true, upToDate: false, image: newImage, replicas: 1, progressDeadlineExceeded: true, stalledImage: newImage},
+	}
+	for _, workload := range stale {
+		if status := upgradeStatus(instance, workload, false); status.Phase != v1beta1.UpgradePhaseProgressing || status.FailedImage != "" {
+			t.Fatalf("upgradeStatus() returned %v for the stale condition of %+v", status, workload)
+		}
+	}
+
+	status = upgradeStatus(instance, workloadStatus{exists: true, upToDate: true, image: newImage, replicas: 1,
+		progressDeadlineExceeded: true, stalledImage: newImage}, false)
+	if status.Phase != v1beta1.UpgradePhaseRolledBack || status.FailedImage != newImage {
+		t.Fatalf("upgradeStatus() returned %v but expected the upgrade to %s to be rolled back", status, newImage)
+	}
+	instance.Status.Upgrade = status
+	if image := workloadImage(instance); image != oldImage {
+		t.Errorf("workloadImage() returned %s but expected the last successful image %s", image, oldImage)
+	}
+
+	instance.Spec.Image.Tag = "v1.3.2"
+	if image := workloadImage(instance); image != generateImage(instance.Spec.Image.Repository, instance.Spec.Image.Tag) {
+		t.Errorf("workloadImage() returned %s but expected the upgrade to be retried with the new image", image)
+	}
+}
+
+func TestWorkloadStatusStalledReplicaSet(t *testing.T) {
+	dep := &appsv1.Deployment{}
+	dep.Spec.Template.Spec.Containers = []corev1.Container{{Name: "nginx"}}
+	dep.Status.Conditions = []appsv1.DeploymentCondition{{
+		Type:    appsv1.DeploymentProgressing,
+		Status:  corev1.ConditionFalse,
+		Reason:  "ProgressDeadlineExceeded",
+		Message: `ReplicaSet "nginx-5d8f7c9b4" has timed out progressing.`,
+	}}
+	if status := workloadStatusFromDeployment(dep); !status.progressDeadlineExceeded || status.stalledReplicaSet != "nginx-5d8f7c9b4" {
+		t.Errorf("workloadStatusFromDeployment() returned %+v", status)
+	}
+}
+
+func TestDefaultImageTag(t *testing.T) {
+	defer func(v *version.Version) { RunningK8sVersion = v }(RunningK8sVersion)
+
+	tests := []struct {
+		k8s string
+		tag string
+	}{
+		{k8s: "", tag: v1beta1.IngressNginxImageTag},
+		{k8s: "v1.24.3", tag: "v1.8.5"},
+		{k8s: "v1.30.1", tag: "v1.12.1"},
+		{k8s: "v1.40.0", tag: v1beta1.IngressNginxImageTag},
+	}
+	for _, test := range tests {
+		RunningK8sVersion = nil
+		if test.k8s != "" {
+			RunningK8sVersion = version.MustParseGeneric(test.k8s)
+		}
+		instance := newTestInstance()
+		if tag := imageTag(instance); tag != test.tag {
+			t.Errorf("imageTag() returned %s on Kubernetes %q but expected %s", tag, test.k8s, test.tag)
+		}
+		if err := checkImageVersion(instance); test.k8s != "v1.40.0" && err != nil {
+			t.Errorf("checkImageVersion() returned %v for the default tag on Kubernetes %q", err, test.k8s)
+		}
+	}
+}
+
+func TestUnsupportedImageIsNotRolledOut(t *testing.T) {
+	defer func(v *version.Version) { RunningK8sVersion = v }(RunningK8sVersion)
+	RunningK8sVersion = version.MustParseGeneric("v1.28.2")
+
+	instance := newTestInstance()
+	instance.Spec.Image.Tag = "v1.3.0"
+	instance.Status.Image = "registry.k8s.io/ingress-nginx/controller:v1.9.6"
+	if err := addDefaultFields(instance); err != nil {
+		t.Fatalf("addDefaultFields() returned %v but an unsupported image must not fail the reconciliation", err)
+	}
+	if checkImageVersion(instance) == nil {
+		t.Fatal("checkImageVersion() accepted ingress-nginx v1.3.0 on Kubernetes 1.28")
+	}
+	if image := workloadImage(instance); image != instance.Status.Image {
+		t.Errorf("workloadImage() returned %s but expected the running image %s", image, instance.Status.Image)
+	}
+
+	instance.Spec.Upgrade = &v1beta1.Upgrade{SkipVersionCheck: true}
+	if image := workloadImage(instance); image != "registry.k8s.io/ingress-nginx/controller:v1.3.0" {
+		t.Errorf("workloadImage() returned %s but expected the image of the spec when the check is skipped", image)
+	}
+}
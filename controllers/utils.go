@@ -18,9 +18,11 @@ package controllers
 
 import (
 	"fmt"
-	"reflect"
+	"sort"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/version"
 	"kubegems.io/ingress-nginx-operator/api/v1beta1"
 )
@@ -30,6 +32,16 @@ const apiVersionUnsupportedError = "server does not support API version"
 // RunningK8sVersion contains the version of k8s
 var RunningK8sVersion *version.Version
 
+// runningK8sVersionAtLeast returns true if the minor version of k8s is at least the given one, or if the version of
+// k8s is unknown.
+func runningK8sVersionAtLeast(minVersion string) bool {
+	if RunningK8sVersion == nil {
+		return true
+	}
+	cluster := version.MustParseGeneric(fmt.Sprintf("%d.%d", RunningK8sVersion.Major(), RunningK8sVersion.Minor()))
+	return cluster.AtLeast(version.MustParseGeneric(minVersion))
+}
+
 // generatePodArgs generate a list of arguments for the Ingress Controller pods based on the CRD.
 func generatePodArgs(instance *v1beta1.NginxIngressController) []string {
 	args := []string{
@@ -41,17 +53,116 @@ func generatePodArgs(instance *v1beta1.NginxIngressController) []string {
 		fmt.Sprintf("--controller-class=kubegems.io/ingress-nginx-%v", instance.Spec.IngressClass),
 	}
 
-	if instance.Spec.WatchNamespace != "" {
-		args = append(args, fmt.Sprintf("-watch-namespace=%v", instance.Spec.WatchNamespace))
+	if restrictedSecurity(instance) {
+		args = append(args,
+			fmt.Sprintf("--http-port=%d", httpPort(instance)),
+			fmt.Sprintf("--https-port=%d", httpsPort(instance)),
+		)
+	}
+
+	if ns := singleWatchNamespace(instance); ns != "" {
+		args = append(args, fmt.Sprintf("-watch-namespace=%v", ns))
+	} else if selector := watchNamespaceSelector(instance); selector != nil {
+		if s, err := metav1.LabelSelectorAsSelector(selector); err == nil {
+			args = append(args, fmt.Sprintf("--watch-namespace-selector=%v", s))
+		}
+	}
+
+	if instance.Spec.DefaultTLS != nil {
+		args = append(args, fmt.Sprintf("--default-ssl-certificate=$(POD_NAMESPACE)/%s", defaultTLSSecretName(instance)))
+	}
+
+	if service := defaultBackendService(instance); service != "" {
+		args = append(args, fmt.Sprintf("--default-backend-service=%s", service))
+	}
+
+	for _, protocol := range []corev1.Protocol{corev1.ProtocolTCP, corev1.ProtocolUDP} {
+		if len(proxiedServices(instance, protocol)) > 0 {
+			args = append(args, fmt.Sprintf("--%s-services-configmap=$(POD_NAMESPACE)/%s",
+				strings.ToLower(string(protocol)), proxiedServicesName(instance, protocol)))
+		}
+	}
+
+	if admissionWebhookEnabled(instance) {
+		args = append(args,
+			fmt.Sprintf("--validating-webhook=:%d", admissionWebhookPort(instance)),
+			fmt.Sprintf("--validating-webhook-certificate=%scert", admissionWebhookCertPath),
+			fmt.Sprintf("--validating-webhook-key=%skey", admissionWebhookCertPath),
+		)
 	}
 
+	args = append(args, geoIPArgs(instance)...)
+
+	if metrics := instance.Spec.Metrics; metrics != nil {
+		args = append(args, fmt.Sprintf("--enable-metrics=%t", metrics.Enable))
+		if metrics.Enable && metrics.PerHost != nil {
+			args = append(args, fmt.Sprintf("--metrics-per-host=%t", *metrics.PerHost))
+		}
+		if metrics.Port != nil {
+			args = append(args, fmt.Sprintf("--healthz-port=%v", *metrics.Port))
+		}
+	}
+
+	return append(args, extraArgs(instance)...)
+}
+
+// extraArgs returns the extra flags of the Ingress Controller sorted by name, so that the order of the map does not
+// change the pod template.
+func extraArgs(instance *v1beta1.NginxIngressController) []string {
+	args := make([]string, 0, len(instance.Spec.ExtraArgs))
+	for flag, value := range instance.Spec.ExtraArgs {
+		name := v1beta1.ExtraArgName(flag)
+		if value == "" {
+			args = append(args, "--"+name)
+		} else {
+			args = append(args, fmt.Sprintf("--%s=%s", name, value))
+		}
+	}
+	sort.Strings(args)
 	return args
 }
 
-// hasDifferentArguments returns whether the arguments of a container are different than the NginxIngressController spec.
-func hasDifferentArguments(container corev1.Container, instance *v1beta1.NginxIngressController) bool {
-	newArgs := generatePodArgs(instance)
-	return !reflect.DeepEqual(newArgs, container.Args)
+// watchedNamespaces returns the namespaces watched by name by the Ingress Controller.
+func watchedNamespaces(instance *v1beta1.NginxIngressController) []string {
+	var namespaces []string
+	if instance.Spec.WatchNamespace != "" {
+		namespaces = append(namespaces, instance.Spec.WatchNamespace)
+	}
+	for _, ns := range instance.Spec.WatchNamespaces {
+		if !containsStr(namespaces, ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// singleWatchNamespace returns the namespace watched by the Ingress Controller if it watches exactly one namespace.
+func singleWatchNamespace(instance *v1beta1.NginxIngressController) string {
+	if namespaces := watchedNamespaces(instance); len(namespaces) == 1 {
+		return namespaces[0]
+	}
+	return ""
+}
+
+// watchNamespaceSelector returns the selector of the namespaces watched by the Ingress Controller, or nil if it
+// watches all the namespaces.
+func watchNamespaceSelector(instance *v1beta1.NginxIngressController) *metav1.LabelSelector {
+	if instance.Spec.NamespaceSelector != nil {
+		return instance.Spec.NamespaceSelector
+	}
+	namespaces := watchedNamespaces(instance)
+	if len(namespaces) == 0 {
+		return nil
+	}
+	return &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      corev1.LabelMetadataName,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   namespaces,
+			},
+		},
+	}
 }
 
 func generateImage(repository string, tag string) string {
@@ -68,14 +179,6 @@ func mergeLabels(origin, toadd map[string]string) map[string]string {
 	return origin
 }
 
-// only cpu and memory
-func HasDifferentResources(origin, newone corev1.ResourceRequirements) bool {
-	return !(origin.Requests.Cpu().Equal(newone.Requests.Cpu().DeepCopy()) &&
-		origin.Requests.Memory().Equal(newone.Requests.Memory().DeepCopy()) &&
-		origin.Limits.Cpu().Equal(newone.Limits.Cpu().DeepCopy()) &&
-		origin.Limits.Memory().Equal(newone.Limits.Memory().DeepCopy()))
-}
-
 func containsStr(src []string, dest string) bool {
 	for _, v := range src {
 		if v == dest {
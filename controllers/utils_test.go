@@ -19,14 +19,87 @@ package controllers
 import (
 	"reflect"
 	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"kubegems.io/ingress-nginx-operator/api/v1beta1"
 )
 
 func TestGeneratePodArgs(t *testing.T) {
-	// TODO
+	port := uint16(9913)
+	perHost := false
+	instance := &v1beta1.NginxIngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec: v1beta1.NginxIngressControllerSpec{
+			IngressClass: "nginx",
+			Metrics:      &v1beta1.Metrics{Enable: true, Port: &port, PerHost: &perHost},
+		},
+	}
+	expected := []string{
+		"/nginx-ingress-controller",
+		"--publish-service=$(POD_NAMESPACE)/nginx",
+		"--configmap=$(POD_NAMESPACE)/nginx",
+		"--election-id=nginx-lock",
+		"--ingress-class=nginx",
+		"--controller-class=kubegems.io/ingress-nginx-nginx",
+		"--enable-metrics=true",
+		"--metrics-per-host=false",
+		"--healthz-port=9913",
+	}
+
+	result := generatePodArgs(instance)
+
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("generatePodArgs(%v) returned %v but expected %v", instance.Spec, result, expected)
+	}
+}
+
+func TestGeneratePodArgsWatchScope(t *testing.T) {
+	tests := []struct {
+		spec     v1beta1.NginxIngressControllerSpec
+		expected string
+	}{
+		{spec: v1beta1.NginxIngressControllerSpec{WatchNamespace: "apps"}, expected: "-watch-namespace=apps"},
+		{spec: v1beta1.NginxIngressControllerSpec{WatchNamespace: "apps", WatchNamespaces: []string{"apps"}}, expected: "-watch-namespace=apps"},
+		{spec: v1beta1.NginxIngressControllerSpec{WatchNamespaces: []string{"web", "apps"}}, expected: "--watch-namespace-selector=kubernetes.io/metadata.name in (apps,web)"},
+		{spec: v1beta1.NginxIngressControllerSpec{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "web"}}}, expected: "--watch-namespace-selector=team=web"},
+	}
+	for _, test := range tests {
+		instance := &v1beta1.NginxIngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+			Spec:       test.spec,
+		}
+		args := generatePodArgs(instance)
+		if last := args[len(args)-1]; last != test.expected {
+			t.Errorf("generatePodArgs(%v) returned watch argument %v but expected %v", test.spec, last, test.expected)
+		}
+	}
 }
 
-func TestHasDifferentArguments(t *testing.T) {
-	// TODO
+func TestGeneratePodArgsExtraArgs(t *testing.T) {
+	instance := newTestInstance()
+	instance.Spec.ExtraArgs = map[string]string{
+		"--enable-ssl-passthrough": "",
+		"annotations-prefix":       "example.com",
+		"v":                        "2",
+	}
+	expected := []string{"--annotations-prefix=example.com", "--enable-ssl-passthrough", "--v=2"}
+	for i := 0; i < 10; i++ {
+		args := generatePodArgs(instance)
+		if result := args[len(args)-len(expected):]; !reflect.DeepEqual(result, expected) {
+			t.Fatalf("generatePodArgs() returned extra args %v but expected %v", result, expected)
+		}
+	}
+	if err := instance.ValidateSpec(); err != nil {
+		t.Errorf("ValidateSpec() returned error %v", err)
+	}
+
+	for _, flag := range []string{"--configmap", "publish-service", "--election-id", "controller-class"} {
+		instance.Spec.ExtraArgs = map[string]string{flag: "value"}
+		if err := instance.ValidateSpec(); err == nil {
+			t.Errorf("ValidateSpec() accepted the operator-managed flag %s", flag)
+		}
+	}
 }
 
 func TestGenerateImage(t *testing.T) {
@@ -58,3 +131,26 @@ func TestMergeLabels(t *testing.T) {
 		t.Errorf("mergeLabels(%v, %v) returned %v but expected %v", origin, toadd, result, expected)
 	}
 }
+
+func TestRunningK8sVersionAtLeast(t *testing.T) {
+	defer func(v *version.Version) { RunningK8sVersion = v }(RunningK8sVersion)
+
+	RunningK8sVersion = nil
+	if !runningK8sVersionAtLeast(autoscalingV2MinK8sVersion) {
+		t.Error("runningK8sVersionAtLeast() returned false for an unknown version")
+	}
+	tests := []struct {
+		running string
+		want    bool
+	}{
+		{"v1.22.15", false},
+		{"v1.23.0-gke.1", true},
+		{"v1.27.3", true},
+	}
+	for _, test := range tests {
+		RunningK8sVersion = version.MustParseGeneric(test.running)
+		if got := runningK8sVersionAtLeast(autoscalingV2MinK8sVersion); got != test.want {
+			t.Errorf("runningK8sVersionAtLeast(%s) with k8s %s returned %t", autoscalingV2MinK8sVersion, test.running, got)
+		}
+	}
+}